@@ -1,16 +1,20 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // HttpClient interface to provide Do(req *http.Request) method
@@ -23,6 +27,29 @@ type ResponseMetaData struct {
 	Header     http.Header
 	StatusCode int
 	Body       []byte
+
+	// Verbose describes the request and response when config.WithVerboseErrors is enabled.
+	Verbose string
+
+	// StreamResponse makes SetResponseMeta leave the body of a successful response unread in
+	// Stream instead of buffering it in Body, e.g. for large downloads. Error responses are still
+	// buffered so they can be parsed. The request context must not be cancelled before Stream is
+	// read and the caller must close it.
+	StreamResponse bool
+	Stream         io.ReadCloser
+}
+
+// BodyReader returns the response body, read from Stream when the response was streamed and from
+// Body otherwise.
+func (rm ResponseMetaData) BodyReader() io.ReadCloser {
+	if rm.Stream != nil {
+		return rm.Stream
+	}
+	return io.NopCloser(bytes.NewReader(rm.Body))
+}
+
+func (rm ResponseMetaData) streamResponse() bool {
+	return rm.StreamResponse
 }
 
 // Stringer to get printable metadata
@@ -30,6 +57,49 @@ func (rm ResponseMetaData) String() string {
 	return fmt.Sprintf("%d\n%s\n%v", rm.StatusCode, string(rm.Body), rm.Header)
 }
 
+// RateLimitLimit returns the number of requests allowed in the rate limit interval from the
+// X-RateLimit-Limit header. It returns false when the header is missing or malformed.
+func (rm ResponseMetaData) RateLimitLimit() (int, bool) {
+	return rm.intHeader("X-RateLimit-Limit")
+}
+
+// RateLimitRemaining returns the number of requests left in the current rate limit interval from
+// the X-RateLimit-Remaining header. It returns false when the header is missing or malformed.
+func (rm ResponseMetaData) RateLimitRemaining() (int, bool) {
+	return rm.intHeader("X-RateLimit-Remaining")
+}
+
+// RateLimitReset returns when the next request can be made. ImageKit sends the X-RateLimit-Reset
+// header as milliseconds from the time of the response, which is taken from the Date header or is
+// the current time. It returns false when the header is missing or malformed.
+func (rm ResponseMetaData) RateLimitReset() (time.Time, bool) {
+	ms, ok := rm.intHeader("X-RateLimit-Reset")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	base, err := http.ParseTime(rm.Header.Get("Date"))
+	if err != nil {
+		base = time.Now()
+	}
+
+	return base.Add(time.Duration(ms) * time.Millisecond), true
+}
+
+// ETag returns the ETag header of the response, e.g. to pass to media.API.FileByIdIfChanged. It is
+// empty when the api did not send one.
+func (rm ResponseMetaData) ETag() string {
+	return rm.Header.Get("ETag")
+}
+
+func (rm ResponseMetaData) intHeader(key string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(rm.Header.Get(key)))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
 // Response is promoted struct to response objects
 type Response struct {
 	ResponseMetaData
@@ -45,9 +115,10 @@ func (resp *Response) Body() []byte {
 	return resp.ResponseMetaData.Body
 }
 
-// ParseError returns error object by parsing the http response body if applicable otherwise returns core error such as ErrUnauthorized, ErrServer etc.
+// ParseError returns an *ApiError parsed from the http response body which wraps the core error
+// matching the status code such as ErrUnauthorized, ErrServer etc.
 func (resp *Response) ParseError() error {
-	var err error
+	var embed error
 	var code = resp.ResponseMetaData.StatusCode
 
 	if code > 199 && code < 300 {
@@ -56,31 +127,43 @@ func (resp *Response) ParseError() error {
 
 	switch code {
 	case 400:
-		err = ParseError(resp.ResponseMetaData.Body, ErrBadRequest)
+		embed = ErrBadRequest
 	case 401:
-		return ErrUnauthorized
+		embed = ErrUnauthorized
 	case 403:
-		err = ParseError(resp.ResponseMetaData.Body, ErrForbidden)
+		embed = ErrForbidden
 	case 404:
-		err = ErrNotFound
+		embed = ErrNotFound
 	case 429:
-		err = ErrTooManyRequests
+		embed = ErrTooManyRequests
 	case 500, 502, 503, 504:
-		err = ErrServer
+		embed = ErrServer
 	default:
-		err = ErrUndefined
+		embed = ErrUndefined
 	}
-	return err
+
+	var ikError = &ApiError{StatusCode: code, err: embed, details: resp.ResponseMetaData.Verbose}
+
+	if err := json.Unmarshal(resp.ResponseMetaData.Body, ikError); err != nil || ikError.Message == "" {
+		ikError.Message = embed.Error()
+	}
+
+	return ikError
 }
 
 type ApiError struct {
-	Message string            `json:"message"`
-	Reason  string            `json:"reason"`
-	Errors  map[string]string `json:"errors"`
-	err     error             `json:"-"`
+	Message    string            `json:"message"`
+	Reason     string            `json:"reason"`
+	Errors     map[string]string `json:"errors"`
+	StatusCode int               `json:"-"`
+	err        error             `json:"-"`
+	details    string            // request and response, see config.WithVerboseErrors
 }
 
 func (e ApiError) Error() string {
+	if e.details != "" {
+		return e.Message + "\n" + e.details
+	}
 	return e.Message
 }
 
@@ -88,6 +171,15 @@ func (e ApiError) Unwrap() error {
 	return e.err
 }
 
+// HTTPStatus returns the status code of the response which caused the error, e.g. to re-emit it
+// when proxying ImageKit errors. It is http.StatusInternalServerError when the code is unknown.
+func (e ApiError) HTTPStatus() int {
+	if e.StatusCode == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.StatusCode
+}
+
 func ParseError(body []byte, embed error) error {
 	var ikError = &ApiError{}
 
@@ -106,10 +198,64 @@ type MetaSetter interface {
 	SetMeta(ResponseMetaData)
 }
 
+// ValidateCustomCoordinates checks that coordinates are formatted as x,y,width,height with
+// non-negative integer offsets and positive integer dimensions.
+func ValidateCustomCoordinates(coordinates string) error {
+	parts := strings.Split(coordinates, ",")
+	if len(parts) != 4 {
+		return fmt.Errorf("custom coordinates %q must be formatted as x,y,width,height", coordinates)
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 || (i >= 2 && n == 0) {
+			return fmt.Errorf("invalid custom coordinates value %q", part)
+		}
+	}
+
+	return nil
+}
+
 // base64DataRegex is the regular expression for detecting base64 encoded strings.
 var base64DataRegex = regexp.MustCompile("^data:([\\w-]+/[\\w\\-+.]+)?(;[\\w-]+=[\\w-]+)*;base64,([a-zA-Z0-9/+\\n=]+)$")
 
+// IsBase64Data reports whether data is a base64 encoded data URI.
+func IsBase64Data(data string) bool {
+	return base64DataRegex.MatchString(data)
+}
+
+// IsValidURL reports whether s is an absolute http, https or ftp url with a host.
+func IsValidURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ftp":
+		return true
+	}
+	return false
+}
+
+// IsLocalFilePath reports whether s is the path of an existing regular file. Urls and data URIs are
+// never treated as file paths.
+func IsLocalFilePath(s string) bool {
+	if s == "" || IsValidURL(s) || strings.HasPrefix(s, "data:") {
+		return false
+	}
+
+	info, err := os.Stat(s)
+	return err == nil && info.Mode().IsRegular()
+}
+
 // StructToParams serializes struct to url.Values, which can be further sent to the http client.
+// Fields are named and omitted according to their json tags. Nil pointers, slices and maps are
+// never sent, so a pointer field distinguishes an unset value from an explicit zero even without
+// omitempty.
+//
+// Slices are sent as indexed params, e.g. tags[0]=a&tags[1]=b. Slices of fields tagged with
+// `ik:"csv"` are joined with commas into a single param instead, e.g. tags=a,b.
 func StructToParams(inputStruct interface{}) (url.Values, error) {
 	var paramsMap map[string]interface{}
 	paramsJSONObj, _ := json.Marshal(inputStruct)
@@ -118,12 +264,33 @@ func StructToParams(inputStruct interface{}) (url.Values, error) {
 		return nil, err
 	}
 
+	csv := csvParams(inputStruct)
+
 	params := url.Values{}
 	for paramName, value := range paramsMap {
+		if value == nil {
+			continue
+		}
+
 		kind := reflect.ValueOf(value).Kind()
 
 		if kind == reflect.Slice || kind == reflect.Array {
 			rVal := reflect.ValueOf(value)
+
+			if csv[paramName] {
+				var items = make([]string, rVal.Len())
+				for i := range items {
+					val, err := encodeParamValue(rVal.Index(i).Interface())
+					if err != nil {
+						return nil, err
+					}
+					items[i] = val
+				}
+
+				params.Add(paramName, strings.Join(items, ","))
+				continue
+			}
+
 			for i := 0; i < rVal.Len(); i++ {
 				item := rVal.Index(i)
 				val, err := encodeParamValue(item.Interface())
@@ -149,6 +316,39 @@ func StructToParams(inputStruct interface{}) (url.Values, error) {
 	return params, nil
 }
 
+// csvParams returns the param names of the struct fields tagged with `ik:"csv"`.
+func csvParams(inputStruct interface{}) map[string]bool {
+	t := reflect.TypeOf(inputStruct)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names = map[string]bool{}
+
+	for _, field := range reflect.VisibleFields(t) {
+		var csv bool
+		for _, opt := range strings.Split(field.Tag.Get("ik"), ",") {
+			csv = csv || opt == "csv"
+		}
+
+		if !csv {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+
+	return names
+}
+
 func encodeParamValue(value interface{}) (string, error) {
 	resBytes, err := json.Marshal(value)
 	if err != nil {
@@ -199,10 +399,21 @@ func DeferredBodyClose(resp *http.Response) {
 	}
 }
 
-// SetResponseMeta assigns given http response data to response objects
-func SetResponseMeta(httpResp *http.Response, respStruct MetaSetter) {
+// ContextWithTimeout returns a copy of ctx which times out after d. Zero d or a deadline already
+// set on ctx leaves ctx unchanged, so explicit deadlines of the caller always win.
+func ContextWithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// SetResponseMeta assigns given http response data to response objects and returns it. The body
+// of a successful response is handed over in Stream rather than read when respStruct has
+// StreamResponse set; httpResp.Body is then replaced by http.NoBody so closing it is harmless.
+func SetResponseMeta(httpResp *http.Response, respStruct MetaSetter) ResponseMetaData {
 	if httpResp == nil {
-		return
+		return ResponseMetaData{}
 	}
 
 	meta := ResponseMetaData{
@@ -210,10 +421,44 @@ func SetResponseMeta(httpResp *http.Response, respStruct MetaSetter) {
 		StatusCode: httpResp.StatusCode,
 	}
 
+	if s, ok := respStruct.(interface{ streamResponse() bool }); ok && s.streamResponse() {
+		meta.StreamResponse = true
+
+		if httpResp.StatusCode > 199 && httpResp.StatusCode < 300 {
+			meta.Stream = httpResp.Body
+			httpResp.Body = http.NoBody
+			respStruct.SetMeta(meta)
+			return meta
+		}
+	}
+
 	if body, err := io.ReadAll(httpResp.Body); err == nil {
 		meta.Body = body
 	}
+	meta.Verbose = verboseDetails(httpResp, meta.Body)
 	respStruct.SetMeta(meta)
+	return meta
+}
+
+// NormalizeTags trims whitespace around tags, optionally lowercases them and removes empty and
+// duplicate tags.
+func NormalizeTags(tags []string, lowercase bool) []string {
+	var seen = map[string]bool{}
+	var result = []string{}
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if lowercase {
+			tag = strings.ToLower(tag)
+		}
+
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
 }
 
 func Bool(b bool) *bool {
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -104,6 +107,10 @@ func Test_ParseError(t *testing.T) {
 			429,
 			ErrTooManyRequests,
 		},
+		"rate-limited": {
+			429,
+			ErrRateLimited,
+		},
 		"undefine err": {
 			600,
 			ErrUndefined,
@@ -137,6 +144,34 @@ func Test_ParseError(t *testing.T) {
 
 }
 
+func TestApiError_HTTPStatus(t *testing.T) {
+	resp := &Response{
+		ResponseMetaData{
+			Body:       []byte(`{"message":"The requested file does not exist.","help":""}`),
+			StatusCode: 404,
+		},
+	}
+
+	var apiErr *ApiError
+	if err := resp.ParseError(); !errors.As(err, &apiErr) {
+		t.Fatalf("expected *ApiError, got %T", err)
+	}
+
+	if apiErr.HTTPStatus() != 404 {
+		t.Errorf("expected status 404, got %d", apiErr.HTTPStatus())
+	}
+
+	if apiErr.Message != "The requested file does not exist." {
+		t.Errorf("unexpected message: %s", apiErr.Message)
+	}
+
+	resp.ResponseMetaData = ResponseMetaData{Body: []byte("<html>Bad Gateway</html>"), StatusCode: 502}
+
+	if err := resp.ParseError(); !errors.As(err, &apiErr) || apiErr.HTTPStatus() != 502 || err.Error() != ErrServer.Error() {
+		t.Errorf("unexpected error for a non json body: %v", err)
+	}
+}
+
 func Test_StructtoParams(t *testing.T) {
 	var cases = map[string]struct {
 		input  any
@@ -162,6 +197,31 @@ func Test_StructtoParams(t *testing.T) {
 			}{2, "test"},
 			result: `{"Name":["test"],"Rank":["2"]}`,
 		},
+		"omit nil": {
+			input: struct {
+				Skip   *int
+				Tags   []string
+				Query  string `json:"searchQuery,omitempty"`
+				Limit  int    `json:"limit,omitempty"`
+				Public *bool
+			}{Skip: new(int), Public: Bool(false)},
+			result: `{"Public":["false"],"Skip":["0"]}`,
+		},
+		"mixed csv and indexed": {
+			input: &struct {
+				Tags   []string `json:"tags" ik:"csv"`
+				Fields []string `json:"fields,omitempty" ik:"fields,csv"`
+				Ids    []int    `json:"ids"`
+				Sizes  []int    `ik:"csv"`
+				Empty  []string `json:"empty,omitempty" ik:"csv"`
+			}{
+				Tags:   []string{"a", "b c"},
+				Fields: []string{"name"},
+				Ids:    []int{1, 2},
+				Sizes:  []int{10, 20},
+			},
+			result: `{"Sizes":["10,20"],"fields":["name"],"ids[0]":["1"],"ids[1]":["2"],"tags":["a,b c"]}`,
+		},
 		"should fail": {
 			input:  "abc",
 			result: "",
@@ -242,6 +302,84 @@ func Test_SetResponseMeta(t *testing.T) {
 	}
 }
 
+func Test_SetResponseMetaStream(t *testing.T) {
+	body := io.NopCloser(strings.NewReader("large file"))
+	httpResp := &http.Response{Body: body, StatusCode: 200}
+
+	var response = &Response{ResponseMetaData{StreamResponse: true}}
+	SetResponseMeta(httpResp, response)
+
+	if response.Body() != nil || httpResp.Body != http.NoBody {
+		t.Error("expected the body to be left unread")
+	}
+
+	data, err := io.ReadAll(response.BodyReader())
+	if err != nil || string(data) != "large file" {
+		t.Errorf("unexpected body %q, %v", data, err)
+	}
+
+	response = &Response{ResponseMetaData{StreamResponse: true}}
+	SetResponseMeta(&http.Response{
+		Body:       io.NopCloser(strings.NewReader(`{"message":"Your request contains invalid fileId parameter."}`)),
+		StatusCode: 400,
+	}, response)
+
+	if response.Stream != nil {
+		t.Error("expected error responses to be buffered")
+	}
+
+	if err = response.ParseError(); !errors.Is(err, ErrBadRequest) || err.Error() != "Your request contains invalid fileId parameter." {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	response = &Response{}
+	SetResponseMeta(&http.Response{Body: io.NopCloser(strings.NewReader("hello")), StatusCode: 200}, response)
+
+	if data, _ = io.ReadAll(response.BodyReader()); string(data) != "hello" {
+		t.Errorf("unexpected buffered body %q", data)
+	}
+}
+
+func TestResponseMetaData_RateLimit(t *testing.T) {
+	meta := ResponseMetaData{Header: http.Header{
+		"X-Ratelimit-Limit":     []string{"100"},
+		"X-Ratelimit-Remaining": []string{" 42 "},
+		"X-Ratelimit-Reset":     []string{"1500"},
+		"Date":                  []string{"Mon, 02 Jan 2023 15:04:05 GMT"},
+	}}
+
+	if n, ok := meta.RateLimitLimit(); !ok || n != 100 {
+		t.Errorf("unexpected limit: %d, %v", n, ok)
+	}
+
+	if n, ok := meta.RateLimitRemaining(); !ok || n != 42 {
+		t.Errorf("unexpected remaining: %d, %v", n, ok)
+	}
+
+	expected := time.Date(2023, 1, 2, 15, 4, 6, 500*int(time.Millisecond), time.UTC)
+	if reset, ok := meta.RateLimitReset(); !ok || !reset.Equal(expected) {
+		t.Errorf("unexpected reset: %s, %v", reset, ok)
+	}
+
+	meta.Header.Del("Date")
+	if reset, ok := meta.RateLimitReset(); !ok || time.Until(reset) > 1500*time.Millisecond || time.Until(reset) < time.Second {
+		t.Errorf("reset should be relative to now: %s, %v", reset, ok)
+	}
+
+	for _, malformed := range []ResponseMetaData{
+		{},
+		{Header: http.Header{"X-Ratelimit-Remaining": []string{"many"}, "X-Ratelimit-Reset": []string{"soon"}}},
+		{Header: http.Header{"X-Ratelimit-Remaining": []string{"-1"}, "X-Ratelimit-Reset": []string{"1.5"}}},
+	} {
+		if _, ok := malformed.RateLimitRemaining(); ok {
+			t.Errorf("expected false for %v", malformed.Header)
+		}
+		if _, ok := malformed.RateLimitReset(); ok {
+			t.Errorf("expected false for %v", malformed.Header)
+		}
+	}
+}
+
 func Test_Bool(t *testing.T) {
 	resp := Bool(true)
 
@@ -255,3 +393,71 @@ func Test_Bool(t *testing.T) {
 		t.Error("invalid bool(false)")
 	}
 }
+
+func Test_NormalizeTags(t *testing.T) {
+	tags := []string{" Summer", "summer ", "", "  ", "Beach"}
+
+	if result := NormalizeTags(tags, false); !cmp.Equal(result, []string{"Summer", "summer", "Beach"}) {
+		t.Errorf("unexpected tags: %q", result)
+	}
+
+	if result := NormalizeTags(tags, true); !cmp.Equal(result, []string{"summer", "beach"}) {
+		t.Errorf("unexpected tags: %q", result)
+	}
+}
+
+func Test_IsBase64Data(t *testing.T) {
+	var cases = map[string]bool{
+		"data:image/gif;base64,R0lGODlhAQABAIAAAAAAAP///yH5BAEAAAAALAAAAAABAAEAAAIBRAA7": true,
+		"data:image/png;name=a;base64,iVBORw0KGgo=":                                      true,
+		"data:image/png;base64,":                                                         false,
+		"https://example.com/a.jpg":                                                      false,
+		"data:text/plain,hello":                                                          false,
+	}
+
+	for data, expected := range cases {
+		if IsBase64Data(data) != expected {
+			t.Errorf("%s: expected %v", data, expected)
+		}
+	}
+}
+
+func Test_IsValidURL(t *testing.T) {
+	var cases = map[string]bool{
+		"https://example.com/a.jpg": true,
+		"http://example.com":        true,
+		"ftp://example.com/a.jpg":   true,
+		"example.com/a.jpg":         false,
+		"file:///tmp/a.jpg":         false,
+		"https://":                  false,
+		"":                          false,
+	}
+
+	for s, expected := range cases {
+		if IsValidURL(s) != expected {
+			t.Errorf("%s: expected %v", s, expected)
+		}
+	}
+}
+
+func Test_IsLocalFilePath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.jpg")
+	if err := os.WriteFile(file, []byte("jpg"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cases = map[string]bool{
+		file:                         true,
+		dir:                          false,
+		"/does/not/exist.jpg":        false,
+		"https://example.com/a.jpg":  false,
+		"data:image/png;base64,iVBO": false,
+	}
+
+	for s, expected := range cases {
+		if IsLocalFilePath(s) != expected {
+			t.Errorf("%s: expected %v", s, expected)
+		}
+	}
+}
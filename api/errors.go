@@ -9,3 +9,55 @@ var ErrTooManyRequests = errors.New("Too Many Requests")
 var ErrServer = errors.New("Server Error")
 var ErrNotFound = errors.New("Not Found")
 var ErrUndefined = errors.New("Undefined Error")
+
+// ErrRateLimited is the same error as ErrTooManyRequests, wrapped by errors of 429 responses.
+var ErrRateLimited = ErrTooManyRequests
+
+// ErrNotModified is returned by conditional requests, such as media.API.FileByIdIfChanged, when
+// the api responds 304 Not Modified.
+var ErrNotModified = errors.New("Not Modified")
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker configured with
+// config.WithCircuitBreaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrCanceled marks the items of a batch operation which were not done because its context was
+// canceled or timed out. Errors matching it also wrap the context error.
+var ErrCanceled = errors.New("canceled")
+
+// ValidationError is returned without sending the request when a parameter is missing or invalid.
+// It wraps ErrBadRequest, the error the api would respond with.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrBadRequest
+}
+
+// CanceledError is the error of an item of a batch operation which was not done because the
+// context err was done. It matches ErrCanceled and wraps err.
+func CanceledError(err error) error {
+	return &canceledError{err}
+}
+
+type canceledError struct {
+	err error
+}
+
+func (e *canceledError) Error() string {
+	return "canceled: " + e.err.Error()
+}
+
+func (e *canceledError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+func (e *canceledError) Unwrap() error {
+	return e.err
+}
@@ -7,6 +7,15 @@ const (
 	AwsAutoTag    TagService = "aws-auto-tagging"
 )
 
+// Status represents the processing state of an extension reported in the extensionStatus of a file
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	StatusPending Status = "pending"
+	StatusFailed  Status = "failed"
+)
+
 // RemoveBgOptions represents different options for removing bg extension
 type RemoveBgOption struct {
 	AddShadow        bool   `json:"add_shadow"`
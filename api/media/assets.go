@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"path"
 	"strings"
 	"time"
 
@@ -13,13 +15,19 @@ import (
 	"gopkg.in/validator.v2"
 )
 
-// ListType represents type of media library files in request filter.
+// ListType represents type of media library files in request filter. ListFile lists only the
+// current version of each file while ListFileVersion also lists their previous versions. ListAll,
+// the api default, lists current files and folders.
 type ListType string
 
 const (
-	ListFile          ListType = "file"
-	ListFTFileVersion ListType = "file-version"
-	ListFolder        ListType = "folder"
+	ListFile        ListType = "file"
+	ListFileVersion ListType = "file-version"
+	ListFolder      ListType = "folder"
+	ListAll         ListType = "all"
+
+	// Deprecated: use ListFileVersion.
+	ListFTFileVersion = ListFileVersion
 )
 
 // Sort specifies sort order for ListFiles results data.
@@ -57,6 +65,16 @@ type FilesParam struct {
 	Tags        string   `json:"tags,omitempty"`
 	Limit       int      `json:"limit,omitempty"`
 	Skip        int      `json:"skip,omitempty"`
+
+	// Query is a structured search query combined with SearchQuery using AND.
+	Query *SearchQuery `json:"-"`
+
+	// Date range filters added to SearchQuery as createdAt and updatedAt clauses. Zero values are
+	// ignored and bounds are inclusive.
+	CreatedAtFrom time.Time `json:"-"`
+	CreatedAtTo   time.Time `json:"-"`
+	UpdatedAtFrom time.Time `json:"-"`
+	UpdatedAtTo   time.Time `json:"-"`
 }
 
 // FileVersionsParam represents filter for getting file's version
@@ -67,26 +85,27 @@ type FileVersionsParam struct {
 
 // File represents media library File details.
 type File struct {
-	FileId            string `json:"fileId"`
-	Name              string `json:"name"`
-	FilePath          string `json:"filePath"`
-	Tags              []string
-	AITags            []map[string]any  `json:"AITags"`
-	VersionInfo       map[string]string `json:"versionInfo"`
-	IsPrivateFile     *bool             `json:"isPrivateFile"`
-	CustomCoordinates *string           `json:"customCoordinates"`
-	Url               string            `json:"url"`
-	Thumbnail         string            `json:"thumbnail"`
-	FileType          FileType          `json:"fileType"`
-	Mime              string            `json:"mime"`
-	Height            int               `json:"height"`
-	Width             int               `json:"Width"`
-	Size              uint64            `json:"size"`
-	HasAlpha          bool              `json:"hasAlpha"`
-	CustomMetadata    map[string]any    `json:"customMetadata,omitempty"`
-	EmbeddedMetadata  map[string]any    `json:"embeddedMetadata"`
-	CreatedAt         time.Time         `json:"createdAt"`
-	UpdatedAt         time.Time         `json:"updatedAt"`
+	FileId            string                      `json:"fileId"`
+	Name              string                      `json:"name"`
+	FilePath          string                      `json:"filePath"`
+	Tags              []string                    `json:"tags"`
+	AITags            []map[string]any            `json:"AITags"`
+	VersionInfo       map[string]string           `json:"versionInfo"`
+	IsPrivateFile     *bool                       `json:"isPrivateFile"`
+	CustomCoordinates *string                     `json:"customCoordinates"`
+	Url               string                      `json:"url"`
+	Thumbnail         string                      `json:"thumbnail"`
+	FileType          FileType                    `json:"fileType"`
+	Mime              string                      `json:"mime"`
+	Height            int                         `json:"height"`
+	Width             int                         `json:"width"`
+	Size              uint64                      `json:"size"`
+	HasAlpha          bool                        `json:"hasAlpha"`
+	CustomMetadata    map[string]any              `json:"customMetadata,omitempty"`
+	EmbeddedMetadata  map[string]any              `json:"embeddedMetadata"`
+	ExtensionStatus   map[string]extension.Status `json:"extensionStatus,omitempty"`
+	CreatedAt         time.Time                   `json:"createdAt"`
+	UpdatedAt         time.Time                   `json:"updatedAt"`
 }
 
 // FilesResponse represents response type of Files().
@@ -109,6 +128,57 @@ type UpdateFileParam struct {
 	Tags              []string               `json:"tags,omitempty"`
 	CustomCoordinates string                 `json:"customCoordinates,omitempty"`
 	CustomMetadata    map[string]any         `json:"customMetadata,omitempty"`
+
+	// Raw holds fields not modelled above, e.g. ones recently added to the api. They are merged
+	// into the request body and the typed fields take precedence on conflicting keys.
+	Raw map[string]any `json:"-"`
+}
+
+// MarshalJSON merges Raw into the typed fields.
+func (p UpdateFileParam) MarshalJSON() ([]byte, error) {
+	type param UpdateFileParam
+
+	typed, err := json.Marshal(param(p))
+	if err != nil || len(p.Raw) == 0 {
+		return typed, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(typed, &fields); err != nil {
+		return nil, err
+	}
+
+	var body = make(map[string]any, len(p.Raw)+len(fields))
+
+	for k, v := range p.Raw {
+		body[k] = v
+	}
+
+	for k, v := range fields {
+		body[k] = v
+	}
+
+	return json.Marshal(body)
+}
+
+// validate rejects conflicting options: "all" combined with other RemoveAITags, and malformed
+// CustomCoordinates.
+func (p UpdateFileParam) validate() error {
+	if len(p.RemoveAITags) > 1 {
+		for _, tag := range p.RemoveAITags {
+			if tag == "all" {
+				return errors.New(`RemoveAITags: "all" can not be combined with other tags`)
+			}
+		}
+	}
+
+	if p.CustomCoordinates != "" {
+		if err := api.ValidateCustomCoordinates(p.CustomCoordinates); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // TagsParam represents parameters to add tags to bulk files
@@ -151,24 +221,30 @@ type DeleteFilesResponse struct {
 	api.Response
 }
 
-// CopyFileParam represents parameters to copy files api
+// CopyFileParam represents parameters to copy files api. The copy api has no overwrite flags: when
+// a file with the same name exists in DestinationPath, the copied file, and its versions when
+// IncludeFileVersions is set, are added to the version history of the existing file, whose tags
+// and custom metadata are kept. Use uploader.UploadParam.OverwriteFile and related flags to replace
+// a file instead.
 type CopyFileParam struct {
 	SourcePath          string `validate:"nonzero" json:"sourceFilePath"`
 	DestinationPath     string `validate:"nonzero" json:"destinationPath"`
 	IncludeFileVersions bool   `json:"includeFileVersions"`
 }
 
-// MoveFileParam represents parameters to move file api
+// MoveFileParam represents parameters to move file api. DestinationPath is the folder the file is
+// moved to, e.g. /archive/. The file keeps its name unless NewFileName is set.
 type MoveFileParam struct {
 	SourcePath      string `validate:"nonzero" json:"sourceFilePath"`
 	DestinationPath string `validate:"nonzero" json:"destinationPath"`
+	NewFileName     string `json:"-"` // renames the file after the move, e.g. old-logo.png
 }
 
 // RenameFileParam represents parameter to rename file api
 type RenameFileParam struct {
 	FilePath    string `validate:"nonzero" json:"filePath"`
 	NewFileName string `validate:"nonzero" json:"newFileName"`
-	PurgeCache  bool   `json:"purgeCache"`
+	PurgeCache  bool   `json:"purgeCache,omitempty"`
 }
 
 // PurgeRequestId contains purge request ids
@@ -182,11 +258,30 @@ type RenameFileResponse struct {
 	api.Response
 }
 
-// JobStatus represents response Data to job status api
+// JobStatus represents response Data to job status api. Errors lists the files which could not
+// be processed when Status is Failed.
 type JobStatus struct {
-	JobId  string `json:"jobId"`
-	Type   string `json:"type"`
-	Status string `json:"status"`
+	JobId  string     `json:"jobId"`
+	Type   string     `json:"type"`
+	Status string     `json:"status"`
+	Errors []JobError `json:"errors,omitempty"`
+}
+
+// Bulk job statuses reported in JobStatus.Status.
+const (
+	JobPending   = "Pending"
+	JobCompleted = "Completed"
+	JobFailed    = "Failed"
+)
+
+// ErrBulkJobFailed is returned by WaitForBulkJob when the job finished with status Failed.
+var ErrBulkJobFailed = errors.New("bulk job failed")
+
+// JobError represents a file which a bulk job failed to process
+type JobError struct {
+	FileId   string `json:"fileId,omitempty"`
+	FilePath string `json:"filePath,omitempty"`
+	Reason   string `json:"reason"`
 }
 
 // JobStatusResponse represents response to job status api
@@ -219,6 +314,12 @@ func (e *ErrorPartialSuccess) Error() string {
 
 // Files retrieves media library files. Filter options can be supplied as FilesParams.
 func (m *API) Files(ctx context.Context, params FilesParam) (*FilesResponse, error) {
+	searchQuery, err := params.searchQuery()
+	if err != nil {
+		return nil, err
+	}
+	params.SearchQuery = searchQuery
+
 	values, err := api.StructToParams(params)
 	if err != nil {
 		return nil, err
@@ -247,11 +348,32 @@ func (m *API) Files(ctx context.Context, params FilesParam) (*FilesResponse, err
 	return response, err
 }
 
-// FileById returns details of single file by provided id
+// FileById returns details of single file by provided id. The api has no option to include the
+// version history, VersionInfo only identifies the current version. Use FileVersions to list
+// all versions.
 func (m *API) FileById(ctx context.Context, fileId string) (*FileResponse, error) {
+	return m.fileById(ctx, fileId, "")
+}
+
+// FileByIdIfChanged is FileById sending etag, taken from ResponseMetaData.ETag of a previous
+// response, as If-None-Match. When the file is unchanged the api responds 304 without a body and
+// api.ErrNotModified is returned, so the details of the previous response can be reused.
+func (m *API) FileByIdIfChanged(ctx context.Context, fileId string, etag string) (*FileResponse, error) {
+	if etag == "" {
+		return nil, errors.New("etag can not be empty")
+	}
+	return m.fileById(ctx, fileId, etag)
+}
+
+func (m *API) fileById(ctx context.Context, fileId string, etag string) (*FileResponse, error) {
 	response := &FileResponse{}
 
-	resp, err := m.get(ctx, fmt.Sprintf("files/%s/details", fileId), response)
+	var header http.Header
+	if etag != "" {
+		header = http.Header{"If-None-Match": []string{etag}}
+	}
+
+	resp, err := m.getWithHeader(ctx, fmt.Sprintf("files/%s/details", fileId), header, response)
 
 	defer api.DeferredBodyClose(resp)
 
@@ -259,6 +381,10 @@ func (m *API) FileById(ctx context.Context, fileId string) (*FileResponse, error
 		return response, err
 	}
 
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return response, api.ErrNotModified
+	}
+
 	if resp.StatusCode != 200 {
 		err = response.ParseError()
 	} else {
@@ -267,7 +393,71 @@ func (m *API) FileById(ctx context.Context, fileId string) (*FileResponse, error
 	return response, err
 }
 
-// FileVersions fetches given file version specified by version id or all versions if versionId not supplied
+// WaitForFile polls FileById every pollInterval until the file is returned or ctx is done. It is
+// useful right after an upload when the file may not be queryable yet. Errors other than
+// api.ErrNotFound stop polling and are returned immediately.
+func (m *API) WaitForFile(ctx context.Context, fileId string, pollInterval time.Duration) (*FileResponse, error) {
+	if fileId == "" {
+		return nil, errors.New("fileId can not be empty")
+	}
+
+	if pollInterval <= 0 {
+		return nil, errors.New("pollInterval must be positive")
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := m.FileById(ctx, fileId)
+		if err == nil || !errors.Is(err, api.ErrNotFound) {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForBulkJob polls BulkJobStatus every pollInterval until the job is no longer pending or
+// ctx is done. A job finishing with status Failed is returned along with ErrBulkJobFailed, see
+// JobStatus.Errors for the files which could not be processed.
+func (m *API) WaitForBulkJob(ctx context.Context, jobId string, pollInterval time.Duration) (*JobStatusResponse, error) {
+	if pollInterval <= 0 {
+		return nil, errors.New("pollInterval must be positive")
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := m.BulkJobStatus(ctx, jobId)
+		if err != nil {
+			return resp, err
+		}
+
+		switch resp.Data.Status {
+		case JobPending:
+		case JobFailed:
+			return resp, ErrBulkJobFailed
+		default:
+			return resp, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// FileVersions fetches all versions of a file when params.VersionId is empty, or the version with
+// that id as the only element of Data. The api returns all versions in one response, there is no
+// pagination.
 func (m *API) FileVersions(ctx context.Context, params FileVersionsParam) (*FilesResponse, error) {
 	parts := []string{"files", params.FileId, "versions"}
 	if params.VersionId != "" {
@@ -310,6 +500,10 @@ func (m *API) UpdateFile(ctx context.Context, fileId string, params UpdateFilePa
 		return nil, errors.New("fileId can not be empty")
 	}
 
+	if err = params.validate(); err != nil {
+		return nil, err
+	}
+
 	resp, err := m.patch(ctx, fmt.Sprintf("files/%s/details", fileId), params, response)
 
 	if err != nil {
@@ -324,11 +518,40 @@ func (m *API) UpdateFile(ctx context.Context, fileId string, params UpdateFilePa
 	return response, err
 }
 
-// AddTags assigns tags to bulk files specified by FileIds
+// requireValue returns an api.ValidationError for field when value is blank.
+func requireValue(field string, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return &api.ValidationError{Field: field, Message: "can not be blank"}
+	}
+	return nil
+}
+
+// requireValues returns an api.ValidationError for field when values is empty.
+func requireValues(field string, values []string) error {
+	if len(values) == 0 {
+		return &api.ValidationError{Field: field, Message: "can not be empty"}
+	}
+	return nil
+}
+
+// AddTags assigns tags to bulk files specified by FileIds. Empty FileIds or Tags are rejected with an
+// api.ValidationError without sending the request.
 func (m *API) AddTags(ctx context.Context, params TagsParam) (*TagsResponse, error) {
 	response := &TagsResponse{}
 	var err error
 
+	if m.Config.API.NormalizeTags {
+		params.Tags = api.NormalizeTags(params.Tags, m.Config.API.LowercaseTags)
+	}
+
+	if err = requireValues("fileIds", params.FileIds); err != nil {
+		return nil, err
+	}
+
+	if err = requireValues("tags", params.Tags); err != nil {
+		return nil, err
+	}
+
 	resp, err := m.post(ctx, "files/addTags", params, response)
 
 	if err != nil {
@@ -361,11 +584,24 @@ func (m *API) AddTags(ctx context.Context, params TagsParam) (*TagsResponse, err
 	return response, err
 }
 
-// RemoveTags removes tags from bulk files specified by FileIds
+// RemoveTags removes tags from bulk files specified by FileIds. Empty FileIds or Tags are rejected
+// with an api.ValidationError without sending the request.
 func (m *API) RemoveTags(ctx context.Context, params TagsParam) (*TagsResponse, error) {
 	response := &TagsResponse{}
 	var err error
 
+	if m.Config.API.NormalizeTags {
+		params.Tags = api.NormalizeTags(params.Tags, m.Config.API.LowercaseTags)
+	}
+
+	if err = requireValues("fileIds", params.FileIds); err != nil {
+		return nil, err
+	}
+
+	if err = requireValues("tags", params.Tags); err != nil {
+		return nil, err
+	}
+
 	resp, err := m.post(ctx, "files/removeTags", params, response)
 
 	if err != nil {
@@ -397,11 +633,20 @@ func (m *API) RemoveTags(ctx context.Context, params TagsParam) (*TagsResponse,
 	return response, err
 }
 
-// RemoveAITags removes tags from bulk files specified by FileIds
+// RemoveAITags removes AI tags from bulk files specified by FileIds. Empty FileIds or AITags are
+// rejected with an api.ValidationError without sending the request.
 func (m *API) RemoveAITags(ctx context.Context, params AITagsParam) (*TagsResponse, error) {
 	response := &TagsResponse{}
 	var err error
 
+	if err = requireValues("fileIds", params.FileIds); err != nil {
+		return nil, err
+	}
+
+	if err = requireValues("AITags", params.AITags); err != nil {
+		return nil, err
+	}
+
 	resp, err := m.post(ctx, "files/removeAITags", params, response)
 
 	if err != nil {
@@ -486,13 +731,17 @@ func (m *API) DeleteBulkFiles(ctx context.Context, param FileIdsParam) (*DeleteF
 	return response, err
 }
 
-// CopyFile copies a file to target path
+// CopyFile copies a file to target path. Blank paths are rejected with an api.ValidationError.
 func (m *API) CopyFile(ctx context.Context, param CopyFileParam) (*api.Response, error) {
 	var err error
 
 	response := &api.Response{}
 
-	if err = validator.Validate(&param); err != nil {
+	if err = requireValue("sourceFilePath", param.SourcePath); err != nil {
+		return nil, err
+	}
+
+	if err = requireValue("destinationPath", param.DestinationPath); err != nil {
 		return nil, err
 	}
 
@@ -508,16 +757,41 @@ func (m *API) CopyFile(ctx context.Context, param CopyFileParam) (*api.Response,
 	return response, err
 }
 
-// MoveFile moves a file to target path
+// MoveFile moves a file to target path. When NewFileName is set the moved file is renamed
+// afterwards. If the rename fails the file stays moved, and the returned error says so.
 func (m *API) MoveFile(ctx context.Context, param MoveFileParam) (*api.Response, error) {
 	var err error
 
 	response := &api.Response{}
 
-	if err = validator.Validate(&param); err != nil {
+	if err = requireValue("sourceFilePath", param.SourcePath); err != nil {
 		return nil, err
 	}
 
+	if err = requireValue("destinationPath", param.DestinationPath); err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(param.SourcePath, "/") {
+		return nil, errors.New("MoveFile: SourcePath must be a file path")
+	}
+
+	if param.NewFileName != "" {
+		// checked before the move, as RenameFile would only reject it once the file is moved
+		if strings.Contains(param.NewFileName, "/") {
+			return nil, errors.New("MoveFile: NewFileName must be a file name, not a path")
+		}
+
+		if len(path.Ext(param.NewFileName)) < 2 {
+			return nil, &api.ValidationError{Field: "newFileName", Message: "must have a file extension"}
+		}
+	}
+
+	newName := param.NewFileName
+	if newName == path.Base(param.SourcePath) {
+		newName = ""
+	}
+
 	resp, err := m.post(ctx, "files/move", &param, response)
 
 	if err != nil {
@@ -525,20 +799,47 @@ func (m *API) MoveFile(ctx context.Context, param MoveFileParam) (*api.Response,
 	}
 
 	if resp.StatusCode != 204 {
-		err = response.ParseError()
+		return response, response.ParseError()
 	}
-	return response, err
+
+	if newName == "" {
+		return response, nil
+	}
+
+	movedPath := path.Join(param.DestinationPath, path.Base(param.SourcePath))
+
+	renamed, err := m.RenameFile(ctx, RenameFileParam{
+		FilePath:    movedPath,
+		NewFileName: newName,
+	})
+	if err != nil {
+		err = fmt.Errorf("MoveFile: moved to %s but not renamed to %s: %w", movedPath, newName, err)
+	}
+	if renamed == nil {
+		return response, err
+	}
+
+	return &renamed.Response, err
 }
 
-// RenameFile renames a file to new name as specified in RenameFileParam struct and optionally includes purge request id
+// RenameFile renames a file to new name as specified in RenameFileParam struct and optionally includes purge request id.
+// A blank FilePath or a NewFileName without extension is rejected with an api.ValidationError.
 func (m *API) RenameFile(ctx context.Context, param RenameFileParam) (*RenameFileResponse, error) {
 	var err error
 	var response = &RenameFileResponse{}
 
-	if err = validator.Validate(&param); err != nil {
+	if err = requireValue("filePath", param.FilePath); err != nil {
 		return nil, err
 	}
 
+	if err = requireValue("newFileName", param.NewFileName); err != nil {
+		return nil, err
+	}
+
+	if len(path.Ext(param.NewFileName)) < 2 {
+		return nil, &api.ValidationError{Field: "newFileName", Message: "must have a file extension"}
+	}
+
 	resp, err := m.put(ctx, "files/rename", &param, response)
 	defer api.DeferredBodyClose(resp)
 
@@ -564,9 +865,17 @@ func (m *API) RestoreVersion(ctx context.Context, param FileVersionsParam) (*Fil
 		return nil, err
 	}
 
-	resp, err := m.delete(ctx, fmt.Sprintf("files/%s/versions/%s/restore",
+	if param.VersionId == "" {
+		return nil, errors.New("versionId can not be empty")
+	}
+
+	resp, err := m.put(ctx, fmt.Sprintf("files/%s/versions/%s/restore",
 		param.FileId, param.VersionId), nil, response)
 
+	if err != nil {
+		return response, err
+	}
+
 	if resp.StatusCode != 200 {
 		err = response.ParseError()
 	} else {
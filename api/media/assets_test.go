@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/imagekit-developer/imagekit-go/api"
 	"github.com/imagekit-developer/imagekit-go/api/extension"
 	iktest "github.com/imagekit-developer/imagekit-go/test"
 )
@@ -107,6 +112,38 @@ func Test_New(t *testing.T) {
 	}
 }
 
+func TestFile_Unmarshal(t *testing.T) {
+	var files []File
+	if err := json.Unmarshal([]byte(respBody), &files); err != nil {
+		t.Fatal(err)
+	}
+
+	file := files[0]
+	if !file.HasAlpha || file.IsPrivateFile == nil || *file.IsPrivateFile || file.Width != 200 || file.Height != 133 {
+		t.Errorf("unexpected file: %+v", file)
+	}
+
+	// absent fields are zero values
+	var minimal File
+	if err := json.Unmarshal([]byte(`{"fileId":"xxx","name":"a.jpg"}`), &minimal); err != nil {
+		t.Fatal(err)
+	}
+
+	if minimal.HasAlpha || minimal.IsPrivateFile != nil || minimal.Tags != nil {
+		t.Errorf("unexpected file: %+v", minimal)
+	}
+
+	// the api field names are kept when marshalling
+	data, _ := json.Marshal(File{Tags: []string{"summer"}, Width: 200})
+
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+
+	if fields["width"] != 200.0 || fields["tags"] == nil || fields["hasAlpha"] != false {
+		t.Errorf("unexpected json: %s", data)
+	}
+}
+
 func TestMedia_Files(t *testing.T) {
 	var expected = assetsArr
 	var cases = map[string]struct {
@@ -130,6 +167,13 @@ func TestMedia_Files(t *testing.T) {
 			},
 			result: "/files?fileType=image&limit=100&path=%2Ftest&searchQuery=createdAt+%3E+%227d%22+AND+name%3A+%22file-name%22&skip=10&sort=ASC_NAME&tags=tag1%2Ctag2&type=file",
 		},
+		"all-versions": {
+			params: FilesParam{
+				Type: ListFileVersion,
+				Path: "/test",
+			},
+			result: "/files?path=%2Ftest&type=file-version",
+		},
 	}
 
 	for name, tc := range cases {
@@ -220,6 +264,103 @@ func TestMedia_FileById(t *testing.T) {
 	})
 }
 
+func TestMedia_FileByIdIfChanged(t *testing.T) {
+	var etag = `"5f1c2a"`
+	var received []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("If-None-Match"))
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(200)
+		w.Write([]byte(singleFileResp))
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	resp, err := mediaApi.FileById(ctx, "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.ETag() != etag || !cmp.Equal(resp.Data, asset) {
+		t.Errorf("unexpected response %q %v", resp.ETag(), resp.Data)
+	}
+
+	resp, err = mediaApi.FileByIdIfChanged(ctx, "123", resp.ETag())
+	if !errors.Is(err, api.ErrNotModified) {
+		t.Errorf("expected ErrNotModified, got %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	resp, err = mediaApi.FileByIdIfChanged(ctx, "123", `"old"`)
+	if err != nil || !cmp.Equal(resp.Data, asset) {
+		t.Errorf("expected changed file, got %v %v", resp.Data, err)
+	}
+
+	if fmt.Sprint(received) != `[ "5f1c2a" "old"]` {
+		t.Errorf("unexpected If-None-Match headers %q", received)
+	}
+
+	if _, err = mediaApi.FileByIdIfChanged(ctx, "123", ""); err == nil {
+		t.Error("expected error for an empty etag")
+	}
+}
+func TestMedia_WaitForFile(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, "{}")
+			return
+		}
+		w.WriteHeader(200)
+		fmt.Fprintln(w, singleFileResp)
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	resp, err := mediaApi.WaitForFile(ctx, "123", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	if !cmp.Equal(resp.Data, asset) {
+		t.Errorf("\n%v\n%v\n", resp.Data, asset)
+	}
+
+	errServer := iktest.NewErrorServer(t)
+	mediaApi.Config.API.Prefix = errServer.Url() + "/"
+
+	errServer.TestErrors(func() error {
+		tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		// 404 keeps polling until the context expires
+		_, err := mediaApi.WaitForFile(tctx, "123", 10*time.Millisecond)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return api.ErrNotFound
+		}
+		return err
+	})
+}
+
 func TestMedia_FileVersions(t *testing.T) {
 	var cases = map[string]struct {
 		fileId     string
@@ -227,13 +368,23 @@ func TestMedia_FileVersions(t *testing.T) {
 		body       string
 		statusCode int
 		shouldFail bool
+		expected   []File
 	}{
 		"all versions": {
+			fileId:     "6283b04dc82abf6294aee010",
+			versionId:  "",
+			body:       respBody,
+			statusCode: 200,
+			shouldFail: false,
+			expected:   assetsArr,
+		},
+		"single version": {
 			fileId:     "6283b04dc82abf6294aee010",
 			versionId:  "v123",
 			body:       singleFileResp,
 			statusCode: 200,
 			shouldFail: false,
+			expected:   []File{asset},
 		},
 		"invalid": {
 			fileId:     "",
@@ -263,7 +414,7 @@ func TestMedia_FileVersions(t *testing.T) {
 				FileId:    tc.fileId,
 				VersionId: tc.versionId,
 			}
-			_, err := mediaApi.FileVersions(ctx, params)
+			resp, err := mediaApi.FileVersions(ctx, params)
 
 			if tc.shouldFail && err == nil {
 				t.Error("expected error")
@@ -275,6 +426,10 @@ func TestMedia_FileVersions(t *testing.T) {
 
 			if !tc.shouldFail {
 				httpTest.Test(expectedUrl, "GET", nil)
+
+				if !cmp.Equal(resp.Data, tc.expected) {
+					t.Errorf("\n%v\n%v\n", resp.Data, tc.expected)
+				}
 			}
 		})
 	}
@@ -287,6 +442,119 @@ func TestMedia_FileVersions(t *testing.T) {
 	})
 }
 
+func TestMedia_UpdateFileBody(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, `{"fileId":"file_id"}`))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	params := UpdateFileParam{
+		RemoveAITags:      []string{"one", "two"},
+		WebhookUrl:        "http://example.com/hook",
+		Extensions:        testExtenstions,
+		Tags:              []string{"abc", "def"},
+		CustomCoordinates: "12,11,22,22",
+		CustomMetadata:    customMetadata,
+	}
+
+	if _, err := mediaApi.UpdateFile(ctx, "file_id", params); err != nil {
+		t.Fatal(err)
+	}
+
+	httpTest.Test("/files/file_id/details", "PATCH", params)
+
+	expected := `{"removeAITags":["one","two"],"webhookUrl":"http://example.com/hook",` +
+		`"extensions":[{"name":"google-auto-tagging","minConfidence":50,"maxTags":10},` +
+		`{"name":"remove-bg","options":{"add_shadow":true,"semitransparency":true,"bg_color":"#000000","bg_image_url":"http://test/test.jpg"}}],` +
+		`"tags":["abc","def"],"customCoordinates":"12,11,22,22","customMetadata":{"brand":"nike","size":10}}`
+
+	if string(httpTest.Body) != expected {
+		t.Errorf("expected body:\n%s\ngot:\n%s", expected, httpTest.Body)
+	}
+}
+
+func TestMedia_UpdateFilePartialBody(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, `{"fileId":"file_id"}`))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	params := UpdateFileParam{
+		CustomMetadata: map[string]any{"brand": "nike"},
+	}
+
+	if _, err := mediaApi.UpdateFile(ctx, "file_id", params); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"customMetadata":{"brand":"nike"}}`
+	if string(httpTest.Body) != expected {
+		t.Errorf("expected body:\n%s\ngot:\n%s", expected, httpTest.Body)
+	}
+}
+
+func TestMedia_UpdateFileRaw(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, `{"fileId":"file_id"}`))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	params := UpdateFileParam{
+		Tags: []string{"abc"},
+		Raw: map[string]any{
+			"publish": map[string]any{"isPublished": true},
+			"tags":    []string{"overridden"},
+		},
+	}
+
+	if _, err := mediaApi.UpdateFile(ctx, "file_id", params); err != nil {
+		t.Fatal(err)
+	}
+
+	httpTest.Test("/files/file_id/details", "PATCH", params)
+
+	expected := `{"publish":{"isPublished":true},"tags":["abc"]}`
+	if string(httpTest.Body) != expected {
+		t.Errorf("expected body:\n%s\ngot:\n%s", expected, httpTest.Body)
+	}
+}
+
+func TestMedia_UpdateFileExtensionStatus(t *testing.T) {
+	var body = `{"fileId":"file_id","name":"beauty.jpg","extensionStatus":{"google-auto-tagging":"success","remove-bg":"pending"}}`
+
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, body))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	params := UpdateFileParam{
+		Extensions: []extension.IExtension{
+			extension.NewAutoTag(extension.GoogleAutoTag, 50, 10),
+			extension.NewRemoveBg(extension.RemoveBgOption{}),
+		},
+	}
+
+	resp, err := mediaApi.UpdateFile(ctx, "file_id", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpTest.Test("/files/file_id/details", "PATCH", params)
+
+	expected := map[string]extension.Status{
+		"google-auto-tagging": extension.StatusSuccess,
+		"remove-bg":           extension.StatusPending,
+	}
+
+	if !cmp.Equal(resp.Data.ExtensionStatus, expected) {
+		t.Errorf("\n%v\n%v", resp.Data.ExtensionStatus, expected)
+	}
+}
+
 func TestMedia_UpdateFile(t *testing.T) {
 	var expected = asset
 	var mockBody = respBody[1 : len(respBody)-1]
@@ -361,6 +629,19 @@ func TestMedia_UpdateFile(t *testing.T) {
 
 		})
 	}
+	for name, params := range map[string]UpdateFileParam{
+		"remove all with other ai tags": {RemoveAITags: []string{"all", "car"}},
+		"invalid custom coordinates":    {CustomCoordinates: "12,11,22"},
+	} {
+		if _, err := mediaApi.UpdateFile(ctx, "file_id", params); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if err := (UpdateFileParam{WebhookUrl: "http://example.com/hook"}).validate(); err != nil {
+		t.Errorf("webhook without extensions: %v", err)
+	}
+
 	errServer := iktest.NewErrorServer(t)
 	mediaApi.Config.API.Prefix = errServer.Url() + "/"
 
@@ -448,7 +729,7 @@ func TestMedia_AddTags(t *testing.T) {
 	errServer := iktest.NewErrorServer(t)
 	mediaApi.Config.API.Prefix = errServer.Url() + "/"
 	errServer.TestErrors(func() error {
-		_, err := mediaApi.AddTags(ctx, TagsParam{})
+		_, err := mediaApi.AddTags(ctx, params)
 		return err
 	})
 }
@@ -533,6 +814,58 @@ func TestMedia_RemoveTags(t *testing.T) {
 	})
 }
 
+func TestMedia_TagNormalization(t *testing.T) {
+	var cases = map[string]struct {
+		normalize bool
+		lowercase bool
+		expected  []string
+	}{
+		"disabled": {
+			expected: []string{" Summer ", "summer", ""},
+		},
+		"trim": {
+			normalize: true,
+			expected:  []string{"Summer", "summer"},
+		},
+		"trim and lowercase": {
+			normalize: true,
+			lowercase: true,
+			expected:  []string{"summer"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpTest := iktest.NewHttp(t)
+			ts := httptest.NewServer(httpTest.Handler(200, `{"successfullyUpdatedFileIds":["xxx"]}`))
+			defer ts.Close()
+
+			cfg := mediaApi.Config
+			cfg.API.Prefix = ts.URL + "/"
+			cfg.API.NormalizeTags = tc.normalize
+			cfg.API.LowercaseTags = tc.lowercase
+
+			m, _ := NewFromConfiguration(&cfg)
+			params := TagsParam{FileIds: []string{"xxx"}, Tags: []string{" Summer ", "summer", ""}}
+
+			for _, fn := range []func(context.Context, TagsParam) (*TagsResponse, error){m.AddTags, m.RemoveTags} {
+				if _, err := fn(ctx, params); err != nil {
+					t.Fatal(err)
+				}
+
+				var body TagsParam
+				if err := json.Unmarshal(httpTest.Body, &body); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(body.Tags, tc.expected) {
+					t.Errorf("expected tags %q, got %q", tc.expected, body.Tags)
+				}
+			}
+		})
+	}
+}
+
 func TestMedia_RemoveAITags(t *testing.T) {
 	var ids = []string{"xxx", "yyy"}
 	var tags = []string{"tag1", "tag2"}
@@ -595,6 +928,72 @@ func TestMedia_RemoveAITags(t *testing.T) {
 	})
 }
 
+func TestMedia_TagsValidation(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	var ids = []string{"xxx"}
+	var tags = []string{"tag1"}
+
+	var cases = map[string]struct {
+		call  func() error
+		field string
+	}{
+		"add tags without file ids": {
+			func() error { _, err := mediaApi.AddTags(ctx, TagsParam{Tags: tags}); return err },
+			"fileIds",
+		},
+		"add tags without tags": {
+			func() error { _, err := mediaApi.AddTags(ctx, TagsParam{FileIds: ids}); return err },
+			"tags",
+		},
+		"remove tags without file ids": {
+			func() error { _, err := mediaApi.RemoveTags(ctx, TagsParam{Tags: tags}); return err },
+			"fileIds",
+		},
+		"remove tags without tags": {
+			func() error {
+				_, err := mediaApi.RemoveTags(ctx, TagsParam{FileIds: ids, Tags: []string{}})
+				return err
+			},
+			"tags",
+		},
+		"remove ai tags without file ids": {
+			func() error { _, err := mediaApi.RemoveAITags(ctx, AITagsParam{AITags: tags}); return err },
+			"fileIds",
+		},
+		"remove ai tags without ai tags": {
+			func() error { _, err := mediaApi.RemoveAITags(ctx, AITagsParam{FileIds: ids}); return err },
+			"AITags",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.call()
+
+			var errValidation *api.ValidationError
+			if !errors.As(err, &errValidation) || errValidation.Field != tc.field {
+				t.Fatalf("expected validation error of %s, got %v", tc.field, err)
+			}
+
+			if !errors.Is(err, api.ErrBadRequest) {
+				t.Error("expected validation error to wrap ErrBadRequest")
+			}
+		})
+	}
+
+	if calls != 0 {
+		t.Errorf("expected no requests, got %d", calls)
+	}
+}
+
 func TestMedia_DeleteFile(t *testing.T) {
 	var err error
 
@@ -781,6 +1180,100 @@ func TestMedia_MoveFile(t *testing.T) {
 	})
 }
 
+func TestMedia_MoveFileRename(t *testing.T) {
+	var requests []string
+	var renameStatus = 200
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+
+		if r.URL.Path == "/files/rename" {
+			w.WriteHeader(renameStatus)
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	var cases = map[string]struct {
+		destination string
+		newName     string
+		requests    []string
+	}{
+		"folder": {
+			destination: "/archive/",
+			requests:    []string{`POST /files/move {"sourceFilePath":"/logos/logo.png","destinationPath":"/archive/"}`},
+		},
+		"folder without slash": {
+			destination: "/archive",
+			requests:    []string{`POST /files/move {"sourceFilePath":"/logos/logo.png","destinationPath":"/archive"}`},
+		},
+		"dotted folder": {
+			destination: "/archive/v1.2",
+			requests:    []string{`POST /files/move {"sourceFilePath":"/logos/logo.png","destinationPath":"/archive/v1.2"}`},
+		},
+		"same name": {
+			destination: "/archive/",
+			newName:     "logo.png",
+			requests:    []string{`POST /files/move {"sourceFilePath":"/logos/logo.png","destinationPath":"/archive/"}`},
+		},
+		"move and rename": {
+			destination: "/archive/v1.2",
+			newName:     "old-logo.png",
+			requests: []string{
+				`POST /files/move {"sourceFilePath":"/logos/logo.png","destinationPath":"/archive/v1.2"}`,
+				`PUT /files/rename {"filePath":"/archive/v1.2/logo.png","newFileName":"old-logo.png"}`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			requests = nil
+
+			if _, err := mediaApi.MoveFile(ctx, MoveFileParam{SourcePath: "/logos/logo.png", DestinationPath: tc.destination, NewFileName: tc.newName}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(requests, tc.requests) {
+				t.Errorf("expected requests:\n%v\ngot:\n%v", tc.requests, requests)
+			}
+		})
+	}
+
+	// a failed rename reports the completed move
+	requests, renameStatus = nil, 404
+
+	_, err := mediaApi.MoveFile(ctx, MoveFileParam{SourcePath: "/logos/logo.png", DestinationPath: "/archive/", NewFileName: "old-logo.png"})
+	if !errors.Is(err, api.ErrNotFound) || !strings.Contains(err.Error(), "moved to /archive/logo.png") {
+		t.Errorf("expected rename error reporting the move, got %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Errorf("expected move and rename requests, got %v", requests)
+	}
+
+	requests = nil
+
+	for name, param := range map[string]MoveFileParam{
+		"folder source":        {SourcePath: "/logos/", DestinationPath: "/archive/"},
+		"path as new name":     {SourcePath: "/logos/logo.png", DestinationPath: "/archive/", NewFileName: "a/logo.png"},
+		"new name without ext": {SourcePath: "/logos/logo.png", DestinationPath: "/archive/", NewFileName: "logo"},
+	} {
+		if _, err := mediaApi.MoveFile(ctx, param); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("expected invalid params to be rejected before the move, got %v", requests)
+	}
+}
+
 func TestMedia_RenameFile(t *testing.T) {
 	var cases = map[string]struct {
 		param      RenameFileParam
@@ -848,6 +1341,83 @@ func TestMedia_RenameFile(t *testing.T) {
 		return err
 	})
 }
+func TestMedia_PathValidation(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	copyFile := func(p CopyFileParam) func() error {
+		return func() error { _, err := mediaApi.CopyFile(ctx, p); return err }
+	}
+	moveFile := func(p MoveFileParam) func() error {
+		return func() error { _, err := mediaApi.MoveFile(ctx, p); return err }
+	}
+	renameFile := func(p RenameFileParam) func() error {
+		return func() error { _, err := mediaApi.RenameFile(ctx, p); return err }
+	}
+
+	var cases = map[string]struct {
+		call  func() error
+		field string
+	}{
+		"copy without paths":          {copyFile(CopyFileParam{}), "sourceFilePath"},
+		"copy without source":         {copyFile(CopyFileParam{DestinationPath: "/dest/"}), "sourceFilePath"},
+		"copy without destination":    {copyFile(CopyFileParam{SourcePath: "/a.jpg", DestinationPath: " "}), "destinationPath"},
+		"move without paths":          {moveFile(MoveFileParam{}), "sourceFilePath"},
+		"move without source":         {moveFile(MoveFileParam{DestinationPath: "/dest/"}), "sourceFilePath"},
+		"move without destination":    {moveFile(MoveFileParam{SourcePath: "/a.jpg"}), "destinationPath"},
+		"rename without params":       {renameFile(RenameFileParam{}), "filePath"},
+		"rename without file path":    {renameFile(RenameFileParam{NewFileName: "b.jpg"}), "filePath"},
+		"rename without new name":     {renameFile(RenameFileParam{FilePath: "/a.jpg"}), "newFileName"},
+		"rename without extension":    {renameFile(RenameFileParam{FilePath: "/a.jpg", NewFileName: "b"}), "newFileName"},
+		"rename with trailing period": {renameFile(RenameFileParam{FilePath: "/a.jpg", NewFileName: "b."}), "newFileName"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.call()
+
+			var errValidation *api.ValidationError
+			if !errors.As(err, &errValidation) || errValidation.Field != tc.field {
+				t.Fatalf("expected validation error of %s, got %v", tc.field, err)
+			}
+		})
+	}
+
+	if calls != 0 {
+		t.Errorf("expected no requests, got %d", calls)
+	}
+}
+
+func TestMedia_RenameFilePurgeCache(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, `{}`))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	if _, err := mediaApi.RenameFile(ctx, RenameFileParam{FilePath: "/a.jpg", NewFileName: "b.jpg"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(httpTest.Body) != `{"filePath":"/a.jpg","newFileName":"b.jpg"}` {
+		t.Errorf("expected purgeCache to be omitted, got %s", httpTest.Body)
+	}
+
+	if _, err := mediaApi.RenameFile(ctx, RenameFileParam{FilePath: "/a.jpg", NewFileName: "b.jpg", PurgeCache: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(httpTest.Body) != `{"filePath":"/a.jpg","newFileName":"b.jpg","purgeCache":true}` {
+		t.Errorf("expected purgeCache to be sent, got %s", httpTest.Body)
+	}
+}
+
 func TestMedia_RestoreVersion(t *testing.T) {
 	var err error
 
@@ -875,13 +1445,18 @@ func TestMedia_RestoreVersion(t *testing.T) {
 	expectedUrl := fmt.Sprintf("/files/%s/versions/%s/restore",
 		param.FileId, param.VersionId)
 
-	httpTest.Test(expectedUrl, "DELETE", param)
+	httpTest.Test(expectedUrl, "PUT", []byte{})
 
 	resp, err = mediaApi.RestoreVersion(ctx, FileVersionsParam{})
 	if err == nil {
 		t.Error("expected error")
 	}
 
+	resp, err = mediaApi.RestoreVersion(ctx, FileVersionsParam{FileId: "file_id"})
+	if err == nil {
+		t.Error("expected error")
+	}
+
 	errServer := iktest.NewErrorServer(t)
 	mediaApi.Config.API.Prefix = errServer.Url() + "/"
 
@@ -895,7 +1470,7 @@ func TestMedia_BulkJobStatus(t *testing.T) {
 	var err error
 	var mockBody = `{"jobId":"job_id","type":"MOVE_FOLDER","status":"Completed"}`
 	var res = JobStatusResponse{
-		Data: JobStatus{"job_id", "MOVE_FOLDER", "Completed"},
+		Data: JobStatus{JobId: "job_id", Type: "MOVE_FOLDER", Status: "Completed"},
 	}
 	_ = json.Unmarshal([]byte(mockBody), &res)
 	var jobId = "job_id"
@@ -917,6 +1492,31 @@ func TestMedia_BulkJobStatus(t *testing.T) {
 
 	httpTest.Test("/bulkJobs/"+jobId, "GET", nil)
 
+	failedBody := `{"jobId":"job_id","type":"COPY_FOLDER","status":"Failed","errors":[{"fileId":"file_1","filePath":"/a/one.jpg","reason":"File already exists"},{"filePath":"/a/two.jpg","reason":"Internal error"}]}`
+	failed := httptest.NewServer(httpTest.Handler(200, failedBody))
+	defer failed.Close()
+
+	mediaApi.Config.API.Prefix = failed.URL + "/"
+
+	resp, err = mediaApi.BulkJobStatus(ctx, jobId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := JobStatus{
+		JobId:  "job_id",
+		Type:   "COPY_FOLDER",
+		Status: "Failed",
+		Errors: []JobError{
+			{FileId: "file_1", FilePath: "/a/one.jpg", Reason: "File already exists"},
+			{FilePath: "/a/two.jpg", Reason: "Internal error"},
+		},
+	}
+
+	if !cmp.Equal(resp.Data, expected) {
+		t.Errorf("\n%v\n%v", resp.Data, expected)
+	}
+
 	resp, err = mediaApi.BulkJobStatus(ctx, "")
 	if err == nil {
 		t.Error("expected error")
@@ -0,0 +1,201 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/imagekit-developer/imagekit-go/api"
+)
+
+// FilesByIds fetches the details of files with given ids using up to concurrency parallel
+// FileById calls. The returned map is keyed by file id and only holds successfully fetched files.
+// The returned errors slice has the same length as ids with errs[i] set when fetching ids[i]
+// failed. When the context is done, queued ids are not fetched and in-flight requests are
+// abandoned, their errors match api.ErrCanceled while the files fetched until then are returned.
+func (m *API) FilesByIds(ctx context.Context, ids []string, concurrency int) (map[string]File, []error) {
+	var files = make(map[string]File, len(ids))
+
+	var mu sync.Mutex
+
+	errs := forEach(ctx, len(ids), concurrency, func(i int) error {
+		if ids[i] == "" {
+			return errors.New("fileId can not be empty")
+		}
+
+		resp, err := m.FileById(ctx, ids[i])
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		files[ids[i]] = resp.Data
+		mu.Unlock()
+		return nil
+	})
+
+	return files, errs
+}
+
+// FileVersion identifies a version of a file.
+type FileVersion struct {
+	FileId    string
+	VersionId string
+}
+
+// MultiError is returned by batch operations, such as DeleteBulkVersions, when some of the items
+// failed. Errors holds the error of each failed item. errors.Is and errors.As match the error of
+// any item, also before Go 1.20, which does not follow Unwrap() []error.
+type MultiError struct {
+	Errors map[FileVersion]error
+}
+
+func (e *MultiError) Error() string {
+	var failed = make([]string, 0, len(e.Errors))
+	for version, err := range e.Errors {
+		failed = append(failed, fmt.Sprintf("%s/%s: %v", version.FileId, version.VersionId, err))
+	}
+	sort.Strings(failed)
+
+	return fmt.Sprintf("%d failed: %s", len(failed), strings.Join(failed, "; "))
+}
+
+// Unwrap returns the errors of the failed items, ordered by file and version id.
+func (e *MultiError) Unwrap() []error {
+	var versions = make([]FileVersion, 0, len(e.Errors))
+	for version := range e.Errors {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].FileId != versions[j].FileId {
+			return versions[i].FileId < versions[j].FileId
+		}
+		return versions[i].VersionId < versions[j].VersionId
+	})
+
+	var errs = make([]error, 0, len(versions))
+	for _, version := range versions {
+		errs = append(errs, e.Errors[version])
+	}
+	return errs
+}
+
+// Is reports whether the error of any failed item matches target.
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Unwrap() {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error of the failed items, in the order of Unwrap, which matches target.
+func (e *MultiError) As(target any) bool {
+	for _, err := range e.Unwrap() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteBulkVersions deletes the versions given by file id using up to concurrency parallel
+// DeleteFileVersion calls. It returns the deleted versions, ordered by file id and the order of
+// their version ids, and a *MultiError holding the error of each version which could not be
+// deleted, e.g. to retry them. A version listed more than once is deleted once. When the context
+// is done, queued versions are not deleted and their errors match api.ErrCanceled. The current
+// version of a file can not be deleted.
+func (m *API) DeleteBulkVersions(ctx context.Context, versions map[string][]string, concurrency int) ([]FileVersion, error) {
+	var fileIds = make([]string, 0, len(versions))
+	for fileId := range versions {
+		fileIds = append(fileIds, fileId)
+	}
+	sort.Strings(fileIds)
+
+	var jobs []FileVersion
+	var seen = map[FileVersion]bool{}
+
+	for _, fileId := range fileIds {
+		for _, versionId := range versions[fileId] {
+			// a version listed twice is deleted once, its error would hide the other's outcome
+			if version := (FileVersion{FileId: fileId, VersionId: versionId}); !seen[version] {
+				seen[version] = true
+				jobs = append(jobs, version)
+			}
+		}
+	}
+
+	errs := forEach(ctx, len(jobs), concurrency, func(i int) error {
+		_, err := m.DeleteFileVersion(ctx, jobs[i].FileId, jobs[i].VersionId)
+		return err
+	})
+
+	var deleted []FileVersion
+	var failed = map[FileVersion]error{}
+
+	for i, err := range errs {
+		if err != nil {
+			failed[jobs[i]] = err
+		} else {
+			deleted = append(deleted, jobs[i])
+		}
+	}
+
+	if len(failed) > 0 {
+		return deleted, &MultiError{Errors: failed}
+	}
+	return deleted, nil
+}
+
+// forEach calls fn for each index below n using up to concurrency goroutines and returns the
+// errors of the calls by index. When ctx is done, queued indices are skipped and their errors, like
+// those of calls failing with the error of ctx, match api.ErrCanceled.
+func forEach(ctx context.Context, n int, concurrency int, fn func(i int) error) []error {
+	var errs = make([]error, n)
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	var jobs = make(chan int)
+
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				err := fn(i)
+				if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+					err = api.CanceledError(ctx.Err())
+				}
+				errs[i] = err
+			}
+		}()
+	}
+
+	i := 0
+loop:
+	for ; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for ; i < n; i++ {
+		errs[i] = api.CanceledError(ctx.Err())
+	}
+
+	return errs
+}
@@ -0,0 +1,228 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/imagekit-developer/imagekit-go/api"
+)
+
+func TestMedia_FilesByIds(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		id := strings.Split(strings.TrimPrefix(r.URL.Path, "/files/"), "/")[0]
+		if id == "missing" {
+			w.WriteHeader(404)
+			w.Write([]byte(`{"message":"The requested file does not exist."}`))
+			return
+		}
+
+		w.WriteHeader(200)
+		w.Write([]byte(`{"fileId":"` + id + `","name":"` + id + `.jpg"}`))
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	ids := []string{"one", "two", "missing", "three", "four"}
+	files, errs := mediaApi.FilesByIds(ctx, ids, 3)
+
+	if len(errs) != len(ids) {
+		t.Fatalf("expected %d errors, got %d", len(ids), len(errs))
+	}
+
+	for i, id := range ids {
+		if id == "missing" {
+			if !errors.Is(errs[i], api.ErrNotFound) {
+				t.Errorf("%s: expected not found error, got %v", id, errs[i])
+			}
+			continue
+		}
+
+		if errs[i] != nil {
+			t.Errorf("%s: unexpected error %v", id, errs[i])
+		}
+
+		if files[id].Name != id+".jpg" {
+			t.Errorf("%s: unexpected file %v", id, files[id])
+		}
+	}
+
+	if len(files) != 4 {
+		t.Errorf("expected 4 files, got %d", len(files))
+	}
+
+	if maxInFlight < 2 || maxInFlight > 3 {
+		t.Errorf("expected up to 3 concurrent requests, got %d", maxInFlight)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	files, errs = mediaApi.FilesByIds(canceled, ids, 2)
+	if len(files) != 0 {
+		t.Errorf("expected no files for a canceled context, got %d", len(files))
+	}
+
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("%s: expected error for a canceled context", ids[i])
+		}
+	}
+}
+
+func TestMedia_FilesByIdsCanceled(t *testing.T) {
+	canceled, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Split(strings.TrimPrefix(r.URL.Path, "/files/"), "/")[0]
+
+		if id == "three" {
+			cancel()
+
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+
+		w.WriteHeader(200)
+		w.Write([]byte(`{"fileId":"` + id + `","name":"` + id + `.jpg"}`))
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	ids := []string{"one", "two", "three", "four", "five"}
+	files, errs := mediaApi.FilesByIds(canceled, ids, 1)
+
+	if len(files) != 2 || files["one"].Name != "one.jpg" || files["two"].Name != "two.jpg" {
+		t.Errorf("expected the files fetched before canceling, got %v", files)
+	}
+
+	for i, id := range ids {
+		if i < 2 {
+			if errs[i] != nil {
+				t.Errorf("%s: unexpected error %v", id, errs[i])
+			}
+			continue
+		}
+
+		if !errors.Is(errs[i], api.ErrCanceled) || !errors.Is(errs[i], context.Canceled) {
+			t.Errorf("%s: expected canceled error, got %v", id, errs[i])
+		}
+	}
+}
+
+func TestMedia_DeleteBulkVersions(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if r.Method != http.MethodDelete {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/current") {
+			w.WriteHeader(400)
+			w.Write([]byte(`{"message":"You cannot delete current version of a file."}`))
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	deleted, err := mediaApi.DeleteBulkVersions(ctx, map[string][]string{
+		"file_b": {"v1", "current"},
+		"file_a": {"v2", "v1"},
+	}, 2)
+
+	expected := []FileVersion{{"file_a", "v2"}, {"file_a", "v1"}, {"file_b", "v1"}}
+	if !cmp.Equal(deleted, expected) {
+		t.Errorf("unexpected deleted versions %v", deleted)
+	}
+
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 1 {
+		t.Fatalf("expected MultiError with one error, got %v", err)
+	}
+
+	if !errors.Is(multi.Errors[FileVersion{"file_b", "current"}], api.ErrBadRequest) {
+		t.Errorf("unexpected errors %v", multi.Errors)
+	}
+
+	// matched by the methods of MultiError, which errors.Is and errors.As call before Go 1.20 too
+	var apiErr *api.ApiError
+	if !multi.Is(api.ErrBadRequest) || multi.Is(api.ErrNotFound) || !multi.As(&apiErr) || apiErr.HTTPStatus() != 400 {
+		t.Errorf("expected aggregate to match the bad request error, got %v", err)
+	}
+
+	if maxInFlight != 2 {
+		t.Errorf("expected up to 2 concurrent requests, got %d", maxInFlight)
+	}
+
+	deleted, err = mediaApi.DeleteBulkVersions(ctx, map[string][]string{"file_a": {"v1"}}, 0)
+	if err != nil || len(deleted) != 1 {
+		t.Errorf("unexpected result %v, %v", deleted, err)
+	}
+
+	// duplicates are deleted once so the outcome of each version is reported once
+	deleted, err = mediaApi.DeleteBulkVersions(ctx, map[string][]string{
+		"file_a": {"v1", "current", "v1", "current"},
+	}, 2)
+
+	if !cmp.Equal(deleted, []FileVersion{{"file_a", "v1"}}) {
+		t.Errorf("unexpected deleted versions %v", deleted)
+	}
+
+	if !errors.As(err, &multi) || len(multi.Errors) != 1 {
+		t.Errorf("expected MultiError with one error, got %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	deleted, err = mediaApi.DeleteBulkVersions(canceled, map[string][]string{"file_a": {"v1", "v2"}}, 1)
+	if len(deleted) != 0 || !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("expected all versions to fail, got %v, %v", deleted, err)
+	}
+
+	for version, err := range multi.Errors {
+		if !errors.Is(err, api.ErrCanceled) {
+			t.Errorf("%v: expected canceled error, got %v", version, err)
+		}
+	}
+}
@@ -4,28 +4,42 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/imagekit-developer/imagekit-go/api"
 	"gopkg.in/validator.v2"
 )
 
+// RequestId identifies a purge cache request, see PurgeCacheStatus.
 type RequestId struct {
 	RequestId string `json:"requestId"`
 }
 
+// PurgeCacheResponse represents the response of PurgeCache.
 type PurgeCacheResponse struct {
 	Data RequestId
 	api.Response
 }
 
+// PurgeCacheParam is the url to remove from the CDN cache, including its transformations and query.
 type PurgeCacheParam struct {
 	Url string `validate:"nonzero" json:"url"`
 }
 
+// PurgeStatus is the status of a purge cache request.
+type PurgeStatus string
+
+const (
+	PurgePending   PurgeStatus = "Pending"
+	PurgeCompleted PurgeStatus = "Completed"
+)
+
+// PurgeCacheStatus is the progress of a purge cache request.
 type PurgeCacheStatus struct {
-	Status string `json:"status"`
+	Status PurgeStatus `json:"status"`
 }
 
+// PurgeCacheStatusResponse represents the response of PurgeCacheStatus.
 type PurgeCacheStatusResponse struct {
 	Data PurgeCacheStatus
 	api.Response
@@ -75,5 +89,28 @@ func (m *API) PurgeCacheStatus(ctx context.Context, requestId string) (*PurgeCac
 		err = json.Unmarshal(response.Body(), &response.Data)
 	}
 	return response, err
+}
 
+// WaitForPurge polls PurgeCacheStatus every pollInterval until the purge request is no longer
+// pending or ctx is done.
+func (m *API) WaitForPurge(ctx context.Context, requestId string, pollInterval time.Duration) (*PurgeCacheStatusResponse, error) {
+	if pollInterval <= 0 {
+		return nil, errors.New("pollInterval must be positive")
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := m.PurgeCacheStatus(ctx, requestId)
+		if err != nil || resp.Data.Status != PurgePending {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
@@ -1,9 +1,14 @@
 package media
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	iktest "github.com/imagekit-developer/imagekit-go/test"
@@ -85,7 +90,7 @@ func TestMedia_PurgeCacheStatus(t *testing.T) {
 	reqId := "62a4b"
 	var rs = PurgeCacheStatusResponse{
 		Data: PurgeCacheStatus{
-			Status: "Pending",
+			Status: PurgePending,
 		},
 	}
 
@@ -120,3 +125,45 @@ func TestMedia_PurgeCacheStatus(t *testing.T) {
 		return err
 	})
 }
+
+func TestMedia_WaitForPurge(t *testing.T) {
+	var polls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/purge/req_1" {
+			w.WriteHeader(404)
+			return
+		}
+
+		polls++
+		status := PurgePending
+		if polls == 3 {
+			status = PurgeCompleted
+		}
+		fmt.Fprintf(w, `{"status":"%s"}`, status)
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	resp, err := mediaApi.WaitForPurge(ctx, "req_1", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Data.Status != PurgeCompleted || polls != 3 {
+		t.Errorf("expected completed purge after 3 polls, got %s after %d", resp.Data.Status, polls)
+	}
+
+	polls = -100
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	if _, err = mediaApi.WaitForPurge(timeout, "req_1", time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	if _, err = mediaApi.WaitForPurge(ctx, "req_1", 0); err == nil {
+		t.Error("expected error for zero poll interval")
+	}
+}
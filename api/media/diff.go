@@ -0,0 +1,84 @@
+package media
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ValueChange holds the old and the new value of a changed attribute. A nil value means the
+// attribute is absent.
+type ValueChange struct {
+	Old any
+	New any
+}
+
+// FileDiff describes the changes between two states of a file.
+type FileDiff struct {
+	TagsAdded      []string
+	TagsRemoved    []string
+	CustomMetadata map[string]ValueChange
+	Name           *ValueChange
+	FilePath       *ValueChange
+	Version        *ValueChange // version id change, i.e. a new version was created or restored
+}
+
+// Empty reports whether there are no changes.
+func (d FileDiff) Empty() bool {
+	return len(d.TagsAdded) == 0 && len(d.TagsRemoved) == 0 && len(d.CustomMetadata) == 0 &&
+		d.Name == nil && d.FilePath == nil && d.Version == nil
+}
+
+// DiffFiles compares the details of file a to file b, e.g. before and after an update. It does not
+// make any request.
+func DiffFiles(a, b File) FileDiff {
+	var diff = FileDiff{
+		TagsAdded:      difference(b.Tags, a.Tags),
+		TagsRemoved:    difference(a.Tags, b.Tags),
+		CustomMetadata: map[string]ValueChange{},
+	}
+
+	for k, v := range a.CustomMetadata {
+		if nv, ok := b.CustomMetadata[k]; !ok || !reflect.DeepEqual(v, nv) {
+			diff.CustomMetadata[k] = ValueChange{Old: v, New: nv}
+		}
+	}
+
+	for k, v := range b.CustomMetadata {
+		if _, ok := a.CustomMetadata[k]; !ok {
+			diff.CustomMetadata[k] = ValueChange{New: v}
+		}
+	}
+
+	if a.Name != b.Name {
+		diff.Name = &ValueChange{Old: a.Name, New: b.Name}
+	}
+
+	if a.FilePath != b.FilePath {
+		diff.FilePath = &ValueChange{Old: a.FilePath, New: b.FilePath}
+	}
+
+	if a.VersionInfo["id"] != b.VersionInfo["id"] {
+		diff.Version = &ValueChange{Old: a.VersionInfo["id"], New: b.VersionInfo["id"]}
+	}
+
+	return diff
+}
+
+// difference returns sorted values of a which are not in b.
+func difference(a, b []string) []string {
+	var in = map[string]bool{}
+	for _, v := range b {
+		in[v] = true
+	}
+
+	var result []string
+	for _, v := range a {
+		if !in[v] {
+			result = append(result, v)
+			in[v] = true
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
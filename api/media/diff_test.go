@@ -0,0 +1,47 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffFiles(t *testing.T) {
+	before := asset
+	before.Tags = []string{"summer", "beach"}
+	before.CustomMetadata = map[string]any{"price": 10.0, "brand": "nike", "size": 9.0}
+
+	after := before
+	after.Tags = []string{"beach", "sale"}
+	after.CustomMetadata = map[string]any{"price": 12.0, "brand": "nike", "color": "red"}
+	after.Name = "renamed.jpg"
+	after.FilePath = "/renamed.jpg"
+	after.VersionInfo = map[string]string{"id": "v3", "name": "Version 3"}
+
+	expected := FileDiff{
+		TagsAdded:   []string{"sale"},
+		TagsRemoved: []string{"summer"},
+		CustomMetadata: map[string]ValueChange{
+			"price": {Old: 10.0, New: 12.0},
+			"size":  {Old: 9.0},
+			"color": {New: "red"},
+		},
+		Name:     &ValueChange{Old: before.Name, New: "renamed.jpg"},
+		FilePath: &ValueChange{Old: before.FilePath, New: "/renamed.jpg"},
+		Version:  &ValueChange{Old: before.VersionInfo["id"], New: "v3"},
+	}
+
+	diff := DiffFiles(before, after)
+
+	if !cmp.Equal(diff, expected) {
+		t.Error(cmp.Diff(expected, diff))
+	}
+
+	if diff.Empty() {
+		t.Error("diff should not be empty")
+	}
+
+	if d := DiffFiles(before, before); !d.Empty() {
+		t.Errorf("expected empty diff, got %v", d)
+	}
+}
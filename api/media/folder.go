@@ -3,6 +3,9 @@ package media
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
 
 	"github.com/imagekit-developer/imagekit-go/api"
 	"gopkg.in/validator.v2"
@@ -23,7 +26,7 @@ type DeleteFolderParam struct {
 type CopyFolderParam struct {
 	SourceFolderPath    string `validate:"nonzero" json:"sourceFolderPath"`
 	DestinationPath     string `validate:"nonzero" json:"destinationPath"`
-	IncludeFileVersions bool   `json:"IncludeFileVersions"`
+	IncludeFileVersions bool   `json:"includeVersions"`
 }
 
 // MoveFolderParam represents parameter to move folder api
@@ -32,14 +35,18 @@ type MoveFolderParam struct {
 	DestinationPath  string `validate:"nonzero" json:"destinationPath"`
 }
 
-// JobIdResponse respresents response struct with JobId for folder operations
-type JobIdResponse struct {
+// BulkJobCreated represents the response of folder operations running as bulk job. JobId can be
+// passed to BulkJobStatus or WaitForBulkJob.
+type BulkJobCreated struct {
 	JobId string `json:"jobId"`
 }
 
+// Deprecated: use BulkJobCreated.
+type JobIdResponse = BulkJobCreated
+
 //FolderResponse respresents struct for response to move folder api.
 type FolderResponse struct {
-	Data JobIdResponse
+	Data BulkJobCreated
 	api.Response
 }
 
@@ -108,6 +115,8 @@ func (m *API) MoveFolder(ctx context.Context, param MoveFolderParam) (*FolderRes
 	return response, err
 }
 
+// CopyFolder copies given folder to new path in media library. The copy runs as bulk job, use the
+// returned JobId with BulkJobStatus or WaitForBulkJob to follow it.
 func (m *API) CopyFolder(ctx context.Context, param CopyFolderParam) (*FolderResponse, error) {
 	var err error
 	var response = &FolderResponse{}
@@ -129,3 +138,54 @@ func (m *API) CopyFolder(ctx context.Context, param CopyFolderParam) (*FolderRes
 	}
 	return response, err
 }
+
+// distinctFoldersMaxFiles is the number of files DistinctFolders looks at before giving up.
+var distinctFoldersMaxFiles = 10000
+
+// ErrTooManyFiles is returned by DistinctFolders when the files under the path exceed the number
+// it looks at. The folders found until then are returned with it.
+var ErrTooManyFiles = errors.New("too many files to list folders")
+
+// DistinctFolders returns the sorted paths of the folders directly under underPath which contain
+// files, derived from the paths of the files listed under underPath. Empty folders are not found.
+// At most 10000 files are looked at, more return ErrTooManyFiles.
+func (m *API) DistinctFolders(ctx context.Context, underPath string) ([]string, error) {
+	prefix := "/" + strings.Trim(underPath, "/")
+	if prefix != "/" {
+		prefix += "/"
+	}
+
+	it := m.FilesIterator(ctx, FilesParam{Type: ListFile, Sort: AscCreated, Path: prefix})
+	defer it.Close()
+
+	var seen = map[string]bool{}
+	var err error
+
+	for n := 0; it.Next(); n++ {
+		if n == distinctFoldersMaxFiles {
+			err = ErrTooManyFiles
+			break
+		}
+
+		path := it.File().FilePath
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+
+		if name, _, ok := strings.Cut(path[len(prefix):], "/"); ok && name != "" {
+			seen[prefix+name] = true
+		}
+	}
+
+	if err == nil {
+		err = it.Err()
+	}
+
+	var folders = make([]string, 0, len(seen))
+	for folder := range seen {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+
+	return folders, err
+}
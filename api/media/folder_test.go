@@ -1,9 +1,14 @@
 package media
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	iktest "github.com/imagekit-developer/imagekit-go/test"
@@ -67,6 +72,10 @@ func TestMedia_DeleteFolder(t *testing.T) {
 
 	httpTest.Test("/folder", "DELETE", nil)
 
+	if expected, _ := json.Marshal(param); !cmp.Equal(httpTest.Body, expected) {
+		t.Errorf("expected body: %s, got: %s", expected, httpTest.Body)
+	}
+
 	_, err = mediaApi.DeleteFolder(ctx, DeleteFolderParam{})
 
 	if err == nil {
@@ -125,12 +134,13 @@ func TestMedia_CopyFolder(t *testing.T) {
 	var err error
 
 	var param = CopyFolderParam{
-		SourceFolderPath: "/src",
-		DestinationPath:  "dest",
+		SourceFolderPath:    "/src",
+		DestinationPath:     "dest",
+		IncludeFileVersions: true,
 	}
 
 	rs := FolderResponse{
-		Data: JobIdResponse{
+		Data: BulkJobCreated{
 			JobId: "xxx",
 		},
 	}
@@ -150,7 +160,7 @@ func TestMedia_CopyFolder(t *testing.T) {
 	if !cmp.Equal(response.Data.JobId, "xxx") {
 		t.Error(response.Data)
 	}
-	httpTest.Test("/bulkJobs/copyFolder", "POST", param)
+	httpTest.Test("/bulkJobs/copyFolder", "POST", []byte(`{"sourceFolderPath":"/src","destinationPath":"dest","includeVersions":true}`))
 
 	response, err = mediaApi.CopyFolder(ctx, CopyFolderParam{})
 
@@ -165,3 +175,111 @@ func TestMedia_CopyFolder(t *testing.T) {
 		return err
 	})
 }
+
+func TestMedia_WaitForBulkJob(t *testing.T) {
+	var polls int
+	var finalStatus = JobCompleted
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /bulkJobs/moveFolder":
+			w.Write([]byte(`{"jobId":"job_1"}`))
+		case "GET /bulkJobs/job_1":
+			polls++
+			status := JobPending
+			if polls == 3 {
+				status = finalStatus
+			}
+			fmt.Fprintf(w, `{"jobId":"job_1","type":"MOVE_FOLDER","status":"%s"}`, status)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	created, err := mediaApi.MoveFolder(ctx, MoveFolderParam{SourceFolderPath: "/src", DestinationPath: "/dest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.Data != (BulkJobCreated{JobId: "job_1"}) {
+		t.Fatalf("unexpected job: %v", created.Data)
+	}
+
+	status, err := mediaApi.WaitForBulkJob(ctx, created.Data.JobId, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Data.Status != JobCompleted || polls != 3 {
+		t.Errorf("expected completed job after 3 polls, got %s after %d", status.Data.Status, polls)
+	}
+
+	polls = 0
+	finalStatus = JobFailed
+
+	if _, err = mediaApi.WaitForBulkJob(ctx, "job_1", time.Millisecond); !errors.Is(err, ErrBulkJobFailed) {
+		t.Errorf("expected ErrBulkJobFailed, got %v", err)
+	}
+
+	polls = -100
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	if _, err = mediaApi.WaitForBulkJob(timeout, "job_1", time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestMedia_DistinctFolders(t *testing.T) {
+	var paths = []string{"/a/b/x.jpg", "/a/y.jpg", "/a/c/d/z.jpg", "/a/b/w.jpg", "/ab/v.jpg", "/a/c/u.jpg"}
+
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+
+		var files []File
+		for _, p := range paths {
+			files = append(files, File{FilePath: p})
+		}
+		json.NewEncoder(w).Encode(files)
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	folders, err := mediaApi.DistinctFolders(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(folders) != "[/a/b /a/c]" {
+		t.Errorf("unexpected folders %v", folders)
+	}
+
+	if query != "path=%2Fa%2F&sort=ASC_CREATED&type=file" {
+		t.Errorf("unexpected query %q", query)
+	}
+
+	max := distinctFoldersMaxFiles
+	distinctFoldersMaxFiles = 3
+	defer func() { distinctFoldersMaxFiles = max }()
+
+	folders, err = mediaApi.DistinctFolders(ctx, "/a/")
+	if !errors.Is(err, ErrTooManyFiles) {
+		t.Errorf("expected ErrTooManyFiles, got %v", err)
+	}
+
+	if fmt.Sprint(folders) != "[/a/b /a/c]" {
+		t.Errorf("unexpected folders %v", folders)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err = mediaApi.DistinctFolders(cancelled, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package media
+
+import (
+	"context"
+	"time"
+)
+
+// defaultFilesLimit is the page size used by the api when FilesParam.Limit is not set. It is also
+// the largest page size the api accepts.
+const defaultFilesLimit = 1000
+
+// IteratorOption configures a FilesIterator.
+type IteratorOption func(*FilesIterator)
+
+// WithPrefetch makes the iterator fetch up to n pages ahead concurrently while the
+// caller processes the current page. Zero disables prefetching.
+func WithPrefetch(n int) IteratorOption {
+	return func(it *FilesIterator) {
+		it.prefetch = n
+	}
+}
+
+type filesPage struct {
+	files []File
+	err   error
+}
+
+// FilesIterator iterates over media library files across pages by advancing FilesParam.Skip.
+//
+//	it := ik.Media.FilesIterator(ctx, media.FilesParam{Limit: 100})
+//	defer it.Close()
+//
+//	for it.Next() {
+//		file := it.File()
+//	}
+//
+//	if err := it.Err(); err != nil {
+//	}
+type FilesIterator struct {
+	api      *API
+	ctx      context.Context
+	cancel   context.CancelFunc
+	params   FilesParam
+	prefetch int
+	pages    chan filesPage
+	aheadErr error // set by fetchAhead before closing pages when it stopped on cancellation
+	buf      []File
+	current  File
+	done     bool
+	err      error
+}
+
+// FilesIterator returns an iterator over all files matching params starting at params.Skip.
+// params.Limit sets the page size and is capped at the api maximum of 1000. Iteration ends after
+// the first page with fewer files than the page size. Call Close when breaking out early.
+func (m *API) FilesIterator(ctx context.Context, params FilesParam, opts ...IteratorOption) *FilesIterator {
+	ctx, cancel := context.WithCancel(ctx)
+
+	if params.Limit > defaultFilesLimit {
+		params.Limit = defaultFilesLimit
+	}
+
+	it := &FilesIterator{
+		api:    m,
+		ctx:    ctx,
+		cancel: cancel,
+		params: params,
+	}
+
+	for _, opt := range opts {
+		opt(it)
+	}
+
+	if it.prefetch > 0 {
+		it.pages = make(chan filesPage, it.prefetch)
+		go it.fetchAhead()
+	}
+
+	return it
+}
+
+// FilesModifiedSince returns an iterator over files updated at or after since, e.g. to sync only
+// the changes since the last backup. The api compares whole seconds, so files updated within the
+// second of since are included again. Folders are not returned.
+func (m *API) FilesModifiedSince(ctx context.Context, since time.Time, opts ...IteratorOption) *FilesIterator {
+	return m.FilesIterator(ctx, FilesParam{
+		Type:          ListFile,
+		Sort:          AscCreated,
+		UpdatedAtFrom: since,
+	}, opts...)
+}
+
+// Next advances the iterator to the next file. It returns false when there are no more
+// files, an error occurred or the context got cancelled.
+func (it *FilesIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for len(it.buf) == 0 {
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		page, ok := it.nextPage()
+		if !ok {
+			// a cancelled prefetch must not look like the end of the files
+			it.err = it.aheadErr
+			return false
+		}
+
+		if page.err != nil {
+			it.err = page.err
+			return false
+		}
+		it.buf = page.files
+	}
+
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	return true
+}
+
+// File returns the current file.
+func (it *FilesIterator) File() File {
+	return it.current
+}
+
+// Err returns the first error encountered during iteration.
+func (it *FilesIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iterator and any prefetching in progress.
+func (it *FilesIterator) Close() {
+	it.cancel()
+}
+
+func (it *FilesIterator) nextPage() (filesPage, bool) {
+	if it.pages != nil {
+		page, ok := <-it.pages
+		return page, ok
+	}
+
+	if it.done {
+		return filesPage{}, false
+	}
+	return it.fetch(), true
+}
+
+// fetchAhead fetches pages in the background until the last page, an error or cancellation. When
+// cancelled while the page can not be queued, its error or the error of the context is left in
+// aheadErr for Next.
+func (it *FilesIterator) fetchAhead() {
+	defer close(it.pages)
+
+	for !it.done {
+		page := it.fetch()
+
+		select {
+		case it.pages <- page:
+		case <-it.ctx.Done():
+			if it.aheadErr = page.err; it.aheadErr == nil {
+				it.aheadErr = it.ctx.Err()
+			}
+			return
+		}
+
+		if page.err != nil {
+			return
+		}
+	}
+}
+
+func (it *FilesIterator) fetch() filesPage {
+	resp, err := it.api.Files(it.ctx, it.params)
+	if err != nil {
+		return filesPage{err: err}
+	}
+
+	limit := it.params.Limit
+	if limit == 0 {
+		limit = defaultFilesLimit
+	}
+
+	if len(resp.Data) < limit {
+		it.done = true
+	}
+
+	it.params.Skip += len(resp.Data)
+	return filesPage{files: resp.Data}
+}
@@ -0,0 +1,283 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/imagekit-developer/imagekit-go/api"
+)
+
+// pagedHandler serves total files in pages according to skip and limit query params.
+func pagedHandler(total int, delay time.Duration, failAtSkip int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		if failAtSkip > 0 && skip == failAtSkip {
+			w.WriteHeader(500)
+			fmt.Fprintln(w, "{}")
+			return
+		}
+
+		var files = []File{}
+		for i := skip; i < total && i < skip+limit; i++ {
+			files = append(files, File{FileId: strconv.Itoa(i)})
+		}
+
+		body, _ := json.Marshal(files)
+		w.WriteHeader(200)
+		w.Write(body)
+	}
+}
+
+func TestMedia_FilesIterator(t *testing.T) {
+	ts := httptest.NewServer(pagedHandler(5, 0, 0))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	for _, prefetch := range []int{0, 2} {
+		it := mediaApi.FilesIterator(ctx, FilesParam{Limit: 2}, WithPrefetch(prefetch))
+
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.File().FileId)
+		}
+		it.Close()
+
+		if it.Err() != nil {
+			t.Error(it.Err())
+		}
+
+		if fmt.Sprint(ids) != "[0 1 2 3 4]" {
+			t.Errorf("prefetch %d: unexpected files %v", prefetch, ids)
+		}
+	}
+}
+
+func TestMedia_FilesIteratorPages(t *testing.T) {
+	var cases = map[string]struct {
+		total    int
+		limit    int
+		requests []string
+	}{
+		"partial last page": {3, 2, []string{"limit=2", "limit=2&skip=2"}},
+		"full last page":    {4, 2, []string{"limit=2", "limit=2&skip=2", "limit=2&skip=4"}},
+		"limit above max":   {1001, 5000, []string{"limit=1000", "limit=1000&skip=1000"}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var requests []string
+			handler := pagedHandler(tc.total, 0, 0)
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.RawQuery)
+				handler(w, r)
+			}))
+			defer ts.Close()
+
+			mediaApi.Config.API.Prefix = ts.URL + "/"
+
+			it := mediaApi.FilesIterator(ctx, FilesParam{Limit: tc.limit})
+			defer it.Close()
+
+			var count int
+			for it.Next() {
+				count++
+			}
+
+			if it.Err() != nil {
+				t.Fatal(it.Err())
+			}
+
+			if count != tc.total || fmt.Sprint(requests) != fmt.Sprint(tc.requests) {
+				t.Errorf("expected %d files from %v, got %d from %v", tc.total, tc.requests, count, requests)
+			}
+		})
+	}
+}
+
+func TestMedia_FilesIteratorBreak(t *testing.T) {
+	var requests int
+	handler := pagedHandler(10, 0, 0)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler(w, r)
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	it := mediaApi.FilesIterator(ctx, FilesParam{Limit: 2})
+
+	for it.Next() {
+		if it.File().FileId == "2" {
+			break
+		}
+	}
+	it.Close()
+
+	if it.Err() != nil || requests != 2 {
+		t.Errorf("expected no error after 2 requests, got %v after %d", it.Err(), requests)
+	}
+}
+
+func TestMedia_FilesIteratorError(t *testing.T) {
+	ts := httptest.NewServer(pagedHandler(10, 0, 4))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	for _, prefetch := range []int{0, 3} {
+		it := mediaApi.FilesIterator(ctx, FilesParam{Limit: 2}, WithPrefetch(prefetch))
+
+		var count int
+		for it.Next() {
+			count++
+		}
+		it.Close()
+
+		if count != 4 {
+			t.Errorf("prefetch %d: expected 4 files before error, got %d", prefetch, count)
+		}
+
+		if !errors.Is(it.Err(), api.ErrServer) {
+			t.Errorf("prefetch %d: expected server error, got %v", prefetch, it.Err())
+		}
+	}
+}
+
+func TestMedia_FilesIteratorPrefetch(t *testing.T) {
+	ts := httptest.NewServer(pagedHandler(6, 50*time.Millisecond, 0))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	var scan = func(opts ...IteratorOption) time.Duration {
+		start := time.Now()
+		it := mediaApi.FilesIterator(ctx, FilesParam{Limit: 2}, opts...)
+		defer it.Close()
+
+		for it.Next() {
+			time.Sleep(25 * time.Millisecond)
+		}
+
+		if it.Err() != nil {
+			t.Error(it.Err())
+		}
+		return time.Since(start)
+	}
+
+	sequential := scan()
+	prefetched := scan(WithPrefetch(1))
+
+	if prefetched >= sequential {
+		t.Errorf("expected prefetching to be faster: sequential %v, prefetched %v", sequential, prefetched)
+	}
+}
+
+func TestMedia_FilesIteratorCancel(t *testing.T) {
+	ts := httptest.NewServer(pagedHandler(100, 0, 0))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	cctx, cancel := context.WithCancel(ctx)
+	it := mediaApi.FilesIterator(cctx, FilesParam{Limit: 2}, WithPrefetch(2))
+	defer it.Close()
+
+	it.Next()
+	cancel()
+
+	for it.Next() {
+	}
+
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", it.Err())
+	}
+}
+
+func TestMedia_FilesIteratorCancelWhileWaiting(t *testing.T) {
+	ts := httptest.NewServer(pagedHandler(100, 100*time.Millisecond, 0))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	for _, opts := range [][]IteratorOption{nil, {WithPrefetch(2)}} {
+		cctx, cancel := context.WithCancel(ctx)
+		it := mediaApi.FilesIterator(cctx, FilesParam{Limit: 2}, opts...)
+
+		// cancelled while Next waits for the first page
+		timer := time.AfterFunc(20*time.Millisecond, cancel)
+
+		if it.Next() {
+			t.Error("expected no file")
+		}
+
+		if !errors.Is(it.Err(), context.Canceled) {
+			t.Errorf("prefetch %v: expected context.Canceled, got %v", len(opts) > 0, it.Err())
+		}
+
+		timer.Stop()
+		it.Close()
+		cancel()
+	}
+}
+
+func TestMedia_FilesModifiedSince(t *testing.T) {
+	var queries []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+
+		var files = []File{}
+		if r.URL.Query().Get("skip") == "" {
+			files = append(files, File{FileId: "a"}, File{FileId: "b"})
+		}
+
+		body, _ := json.Marshal(files)
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	since := time.Date(2022, 6, 1, 12, 30, 15, 500, time.FixedZone("CEST", 2*60*60))
+	it := mediaApi.FilesModifiedSince(ctx, since)
+	defer it.Close()
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.File().FileId)
+	}
+
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+
+	if fmt.Sprint(ids) != "[a b]" {
+		t.Errorf("unexpected files %v", ids)
+	}
+
+	expected := url.Values{
+		"searchQuery": []string{`updatedAt >= "2022-06-01T10:30:15Z"`},
+		"sort":        []string{"ASC_CREATED"},
+		"type":        []string{"file"},
+	}.Encode()
+
+	if len(queries) != 1 || queries[0] != expected {
+		t.Errorf("unexpected queries %v", queries)
+	}
+}
@@ -33,12 +33,15 @@ func New() (*API, error) {
 func NewFromConfiguration(c *config.Configuration) (*API, error) {
 	return &API{
 		Config: *c,
-		Client: &http.Client{},
+		Client: api.NewClient(c),
 		Logger: logger.New(),
 	}, nil
 }
 
 func (m *API) post(ctx context.Context, url string, data interface{}, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	url = api.BuildPath(m.Config.API.Prefix, url)
 	var err error
 	var body []byte
@@ -56,19 +59,26 @@ func (m *API) post(ctx context.Context, url string, data interface{}, ms api.Met
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
 	if err != nil {
 		err = fmt.Errorf("client.Do %w", err)
 	}
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 	return resp, err
 }
 
 func (m *API) get(ctx context.Context, url string, ms api.MetaSetter) (*http.Response, error) {
+	return m.getWithHeader(ctx, url, nil, ms)
+}
+
+// getWithHeader is get sending header in addition, e.g. for conditional requests.
+func (m *API) getWithHeader(ctx context.Context, url string, header http.Header, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	url = api.BuildPath(m.Config.API.Prefix, url)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 
@@ -76,17 +86,23 @@ func (m *API) get(ctx context.Context, url string, ms api.MetaSetter) (*http.Res
 		return nil, err
 	}
 
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
+	for key, values := range header {
+		req.Header[key] = values
+	}
 
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 
 	return resp, err
 }
 
 func (m *API) delete(ctx context.Context, url string, data interface{}, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	var err error
 	url = api.BuildPath(m.Config.API.Prefix, url)
 	var body []byte
@@ -105,17 +121,19 @@ func (m *API) delete(ctx context.Context, url string, data interface{}, ms api.M
 		return nil, err
 	}
 
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 
 	return resp, err
 }
 
 func (m *API) patch(ctx context.Context, url string, data interface{}, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	url = api.BuildPath(m.Config.API.Prefix, url)
 	var err error
 	var body []byte
@@ -132,17 +150,19 @@ func (m *API) patch(ctx context.Context, url string, data interface{}, ms api.Me
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 
 	return resp, err
 }
 
 func (m *API) put(ctx context.Context, url string, data interface{}, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	url = api.BuildPath(m.Config.API.Prefix, url)
 	var err error
 	var body []byte
@@ -159,12 +179,11 @@ func (m *API) put(ctx context.Context, url string, data interface{}, ms api.Meta
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 
 	return resp, err
 }
@@ -0,0 +1,43 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func slowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(200)
+		w.Write([]byte(singleFileResp))
+	}))
+}
+
+func TestMedia_Timeout(t *testing.T) {
+	ts := slowServer(100 * time.Millisecond)
+	defer ts.Close()
+
+	cfg := mediaApi.Config
+	api, err := NewFromConfiguration(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.Config.API.Prefix = ts.URL + "/"
+	api.Config.API.RequestTimeout = 20 * time.Millisecond
+	api.Config.API.UploadRequestTimeout = time.Minute
+
+	if _, err = api.FileById(ctx, "123"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if _, err = api.FileById(dctx, "123"); err != nil {
+		t.Errorf("context deadline should win over default timeout: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package media
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/bits"
+)
+
+// PHashDistance returns the Hamming distance between two hex encoded perceptual hashes, such as
+// the PHash of file metadata. Images with a distance of up to about 10 of the 64 bits are usually
+// visually similar, 0 means identical hashes. Both hashes must be valid hex of equal length.
+func PHashDistance(hash1, hash2 string) (int, error) {
+	if len(hash1) != len(hash2) {
+		return 0, errors.New("PHashDistance: hashes must have the same length")
+	}
+
+	a, err := hex.DecodeString(padHex(hash1))
+	if err != nil {
+		return 0, errors.New("PHashDistance: hash1 is not hex encoded")
+	}
+
+	b, err := hex.DecodeString(padHex(hash2))
+	if err != nil {
+		return 0, errors.New("PHashDistance: hash2 is not hex encoded")
+	}
+
+	var distance int
+	for i := range a {
+		distance += bits.OnesCount8(a[i] ^ b[i])
+	}
+
+	return distance, nil
+}
+
+// padHex prepends a zero to hex strings of odd length, which hex.DecodeString rejects.
+func padHex(s string) string {
+	if len(s)%2 == 1 {
+		return "0" + s
+	}
+	return s
+}
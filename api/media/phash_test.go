@@ -0,0 +1,43 @@
+package media
+
+import "testing"
+
+func TestPHashDistance(t *testing.T) {
+	var cases = map[string]struct {
+		hash1    string
+		hash2    string
+		distance int
+		err      bool
+	}{
+		"identical":     {"63433b3ccf8e1ebe", "63433b3ccf8e1ebe", 0, false},
+		"one bit":       {"63433b3ccf8e1ebe", "63433b3ccf8e1ebf", 1, false},
+		"all bits":      {"0000000000000000", "ffffffffffffffff", 64, false},
+		"case":          {"63433B3CCF8E1EBE", "63433b3ccf8e1ebe", 0, false},
+		"odd length":    {"f0f", "0f0", 12, false},
+		"empty":         {"", "", 0, false},
+		"length":        {"63433b3ccf8e1ebe", "63433b3c", 0, true},
+		"invalid hash1": {"63433b3ccf8e1ebz", "63433b3ccf8e1ebe", 0, true},
+		"invalid hash2": {"63433b3ccf8e1ebe", "not a hash here!", 0, true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			distance, err := PHashDistance(tc.hash1, tc.hash2)
+
+			if tc.err {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if distance != tc.distance {
+				t.Errorf("expected distance %d, got %d", tc.distance, distance)
+			}
+		})
+	}
+}
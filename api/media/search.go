@@ -0,0 +1,262 @@
+package media
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var fieldNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// SearchQuery builds the searchQuery expression of FilesParam. Conditions are joined with AND.
+//
+//	q, err := media.Query().Field("size").Gt(1000).CustomField("price").Gte(10).Build()
+type SearchQuery struct {
+	clauses []string
+	err     error
+}
+
+// Condition is a pending comparison on a field of a SearchQuery.
+type Condition struct {
+	query *SearchQuery
+	field string
+}
+
+// Query returns a new empty SearchQuery.
+func Query() *SearchQuery {
+	return &SearchQuery{}
+}
+
+// Field starts a condition on a file attribute such as name, size or createdAt.
+func (q *SearchQuery) Field(name string) *Condition {
+	if !fieldNameRegex.MatchString(name) {
+		q.setErr(fmt.Errorf("invalid search field name %q", name))
+	}
+	return &Condition{query: q, field: name}
+}
+
+// CustomField starts a condition on a custom metadata field, i.e. customMetadata.<name>.
+func (q *SearchQuery) CustomField(name string) *Condition {
+	if !fieldNameRegex.MatchString(name) {
+		q.setErr(fmt.Errorf("invalid custom metadata field name %q", name))
+	}
+	return &Condition{query: q, field: "customMetadata." + name}
+}
+
+// And returns a query matching all of queries.
+func And(queries ...*SearchQuery) *SearchQuery {
+	var q = Query()
+
+	for _, sub := range queries {
+		if sub.err != nil {
+			q.setErr(sub.err)
+		}
+		q.clauses = append(q.clauses, sub.clauses...)
+	}
+	return q
+}
+
+// Or returns a query matching any of queries.
+//
+//	media.Or(media.Query().Field("format").Eq("png"), media.Query().Field("size").Lt(1000))
+func Or(queries ...*SearchQuery) *SearchQuery {
+	var q = Query()
+	var groups []string
+
+	for _, sub := range queries {
+		built, err := sub.Build()
+		if err != nil {
+			q.setErr(err)
+			continue
+		}
+
+		if len(sub.clauses) > 1 {
+			built = "(" + built + ")"
+		}
+		if built != "" {
+			groups = append(groups, built)
+		}
+	}
+
+	switch len(groups) {
+	case 0:
+	case 1:
+		q.clauses = groups
+	default:
+		q.clauses = []string{"(" + strings.Join(groups, " OR ") + ")"}
+	}
+	return q
+}
+
+// Build returns the compiled query or the first error encountered while building it.
+func (q *SearchQuery) Build() (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	return strings.Join(q.clauses, " AND "), nil
+}
+
+// String returns the compiled query. Use Build to get validation errors.
+func (q *SearchQuery) String() string {
+	s, _ := q.Build()
+	return s
+}
+
+func (q *SearchQuery) setErr(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+func (c *Condition) compare(op string, value any) *SearchQuery {
+	v, err := formatSearchValue(value)
+	if err != nil {
+		c.query.setErr(fmt.Errorf("%s: %w", c.field, err))
+		return c.query
+	}
+
+	c.query.clauses = append(c.query.clauses, c.field+" "+op+" "+v)
+	return c.query
+}
+
+// Eq adds field = value condition.
+func (c *Condition) Eq(value any) *SearchQuery {
+	return c.compare("=", value)
+}
+
+// Ne adds field != value condition.
+func (c *Condition) Ne(value any) *SearchQuery {
+	return c.compare("!=", value)
+}
+
+// Gt adds field > value condition.
+func (c *Condition) Gt(value any) *SearchQuery {
+	return c.compare(">", value)
+}
+
+// Gte adds field >= value condition.
+func (c *Condition) Gte(value any) *SearchQuery {
+	return c.compare(">=", value)
+}
+
+// Lt adds field < value condition.
+func (c *Condition) Lt(value any) *SearchQuery {
+	return c.compare("<", value)
+}
+
+// Lte adds field <= value condition.
+func (c *Condition) Lte(value any) *SearchQuery {
+	return c.compare("<=", value)
+}
+
+// Between adds from <= field <= to conditions.
+func (c *Condition) Between(from any, to any) *SearchQuery {
+	c.compare(">=", from)
+	return c.compare("<=", to)
+}
+
+// In adds field IN [values] condition, e.g. for tags or format.
+func (c *Condition) In(values ...any) *SearchQuery {
+	return c.list("IN", values)
+}
+
+// NotIn adds field NOT IN [values] condition.
+func (c *Condition) NotIn(values ...any) *SearchQuery {
+	return c.list("NOT IN", values)
+}
+
+func (c *Condition) list(op string, values []any) *SearchQuery {
+	if len(values) == 0 {
+		c.query.setErr(fmt.Errorf("%s: %s requires at least one value", c.field, op))
+		return c.query
+	}
+
+	var items = make([]string, len(values))
+	for i, value := range values {
+		v, err := formatSearchValue(value)
+		if err != nil {
+			c.query.setErr(fmt.Errorf("%s: %w", c.field, err))
+			return c.query
+		}
+		items[i] = v
+	}
+
+	c.query.clauses = append(c.query.clauses, c.field+" "+op+" ["+strings.Join(items, ", ")+"]")
+	return c.query
+}
+
+// searchQuery returns SearchQuery extended with the clauses of the date range filters.
+func (p FilesParam) searchQuery() (string, error) {
+	if p.Query != nil {
+		built, err := p.Query.Build()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case p.SearchQuery == "":
+			p.SearchQuery = built
+		case built != "":
+			p.SearchQuery = "(" + p.SearchQuery + ") AND " + built
+		}
+	}
+
+	var q = Query()
+
+	for _, r := range []struct {
+		field    string
+		from, to time.Time
+	}{
+		{"createdAt", p.CreatedAtFrom, p.CreatedAtTo},
+		{"updatedAt", p.UpdatedAtFrom, p.UpdatedAtTo},
+	} {
+		if !r.from.IsZero() && !r.to.IsZero() && r.from.After(r.to) {
+			return "", fmt.Errorf("%s range: from is after to", r.field)
+		}
+
+		if !r.from.IsZero() {
+			q.Field(r.field).Gte(r.from)
+		}
+
+		if !r.to.IsZero() {
+			q.Field(r.field).Lte(r.to)
+		}
+	}
+
+	dates, err := q.Build()
+	if err != nil || dates == "" {
+		return p.SearchQuery, err
+	}
+
+	if p.SearchQuery == "" {
+		return dates, nil
+	}
+
+	return "(" + p.SearchQuery + ") AND " + dates, nil
+}
+
+// formatSearchValue formats numbers and booleans as is, and quotes strings and dates.
+func formatSearchValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return quoteSearchValue(v), nil
+	case time.Time:
+		return quoteSearchValue(v.UTC().Format(time.RFC3339)), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", value)
+}
+
+func quoteSearchValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
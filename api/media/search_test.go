@@ -0,0 +1,163 @@
+package media
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	iktest "github.com/imagekit-developer/imagekit-go/test"
+)
+
+func TestSearchQuery(t *testing.T) {
+	var cases = map[string]struct {
+		query      *SearchQuery
+		result     string
+		shouldFail bool
+	}{
+		"custom field": {
+			query:  Query().CustomField("price").Gte(10),
+			result: `customMetadata.price >= 10`,
+		},
+		"combined": {
+			query: Query().
+				Field("name").Eq(`my "file".jpg`).
+				Field("createdAt").Gt(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)).
+				CustomField("onSale").Eq(true).
+				CustomField("weight").Lt(2.5),
+			result: `name = "my \"file\".jpg" AND createdAt > "2022-06-01T00:00:00Z" AND customMetadata.onSale = true AND customMetadata.weight < 2.5`,
+		},
+		"in": {
+			query:  Query().Field("tags").In("summer", `say "hi"`).Field("format").NotIn("png", "gif"),
+			result: `tags IN ["summer", "say \"hi\""] AND format NOT IN ["png", "gif"]`,
+		},
+		"between": {
+			query:  Query().Field("size").Between(1000, 5000),
+			result: `size >= 1000 AND size <= 5000`,
+		},
+		"or": {
+			query: Or(
+				Query().Field("format").Eq("png"),
+				Query().Field("size").Between(1, 10),
+			),
+			result: `(format = "png" OR (size >= 1 AND size <= 10))`,
+		},
+		"and with or": {
+			query: And(
+				Query().Field("type").Eq("file"),
+				Or(Query().Field("width").Gt(100), Query().Field("height").Gt(100)),
+			),
+			result: `type = "file" AND (width > 100 OR height > 100)`,
+		},
+		"empty in": {
+			query:      Query().Field("tags").In(),
+			shouldFail: true,
+		},
+		"invalid nested query": {
+			query:      Or(Query().Field("size").Eq(1), Query().Field("name").Eq(struct{}{})),
+			shouldFail: true,
+		},
+		"invalid custom field name": {
+			query:      Query().CustomField("price) OR (size").Gte(10),
+			shouldFail: true,
+		},
+		"invalid field name": {
+			query:      Query().Field("").Eq("x"),
+			shouldFail: true,
+		},
+		"unsupported value": {
+			query:      Query().CustomField("price").Eq([]int{1}),
+			shouldFail: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result, err := tc.query.Build()
+
+			if tc.shouldFail {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != tc.result {
+				t.Errorf("expected: %s\ngot: %s", tc.result, result)
+			}
+		})
+	}
+}
+
+func TestFilesParam_DateRange(t *testing.T) {
+	var from = time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	var to = time.Date(2022, 6, 30, 23, 59, 59, 0, time.FixedZone("CEST", 2*60*60))
+
+	var cases = map[string]struct {
+		params     FilesParam
+		result     string
+		shouldFail bool
+	}{
+		"created range": {
+			params: FilesParam{CreatedAtFrom: from, CreatedAtTo: to},
+			result: `createdAt >= "2022-06-01T00:00:00Z" AND createdAt <= "2022-06-30T21:59:59Z"`,
+		},
+		"updated since with query": {
+			params: FilesParam{SearchQuery: `size > 1000 OR format = "png"`, UpdatedAtFrom: from},
+			result: `(size > 1000 OR format = "png") AND updatedAt >= "2022-06-01T00:00:00Z"`,
+		},
+		"query only": {
+			params: FilesParam{SearchQuery: `size > 1000`},
+			result: `size > 1000`,
+		},
+		"structured query": {
+			params: FilesParam{SearchQuery: `name = "a.jpg"`, Query: Query().Field("tags").In("x"), UpdatedAtFrom: from},
+			result: `((name = "a.jpg") AND tags IN ["x"]) AND updatedAt >= "2022-06-01T00:00:00Z"`,
+		},
+		"from after to": {
+			params:     FilesParam{UpdatedAtFrom: to, UpdatedAtTo: from},
+			shouldFail: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result, err := tc.params.searchQuery()
+
+			if tc.shouldFail {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != tc.result {
+				t.Errorf("expected: %s\ngot: %s", tc.result, result)
+			}
+		})
+	}
+
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, "[]"))
+	defer ts.Close()
+
+	mediaApi.Config.API.Prefix = ts.URL + "/"
+
+	if _, err := mediaApi.Files(ctx, FilesParam{CreatedAtFrom: from, CreatedAtTo: to}); err != nil {
+		t.Fatal(err)
+	}
+
+	httpTest.Test("/files?searchQuery="+url.QueryEscape(`createdAt >= "2022-06-01T00:00:00Z" AND createdAt <= "2022-06-30T21:59:59Z"`), "GET", nil)
+
+	if _, err := mediaApi.Files(ctx, FilesParam{CreatedAtFrom: to, CreatedAtTo: from}); err == nil {
+		t.Error("expected error for an invalid range")
+	}
+}
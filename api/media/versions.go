@@ -0,0 +1,41 @@
+package media
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoVersions is returned by LatestVersion when the api returns no versions for a file.
+var ErrNoVersions = errors.New("file has no versions")
+
+// LatestVersion returns the most recently created version of a file, which is the current one.
+// A file which was never overwritten has a single version.
+func (m *API) LatestVersion(ctx context.Context, fileId string) (File, error) {
+	resp, err := m.FileVersions(ctx, FileVersionsParam{FileId: fileId})
+	if err != nil {
+		return File{}, err
+	}
+
+	if len(resp.Data) == 0 {
+		return File{}, ErrNoVersions
+	}
+
+	latest := resp.Data[0]
+	for _, version := range resp.Data[1:] {
+		if !version.CreatedAt.Before(latest.CreatedAt) {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
+
+// VersionCount returns the number of versions of a file including the current one.
+func (m *API) VersionCount(ctx context.Context, fileId string) (int, error) {
+	resp, err := m.FileVersions(ctx, FileVersionsParam{FileId: fileId})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(resp.Data), nil
+}
@@ -0,0 +1,70 @@
+package media
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	iktest "github.com/imagekit-developer/imagekit-go/test"
+)
+
+func TestMedia_LatestVersion(t *testing.T) {
+	var cases = map[string]struct {
+		body    string
+		count   int
+		version string
+		err     error
+	}{
+		"single version": {
+			body:    `[{"fileId":"file_1","versionInfo":{"id":"v1","name":"Version 1"},"createdAt":"2022-06-01T10:00:00.000Z"}]`,
+			count:   1,
+			version: "v1",
+		},
+		"multiple versions": {
+			body: `[{"fileId":"file_1","versionInfo":{"id":"v2","name":"Version 2"},"createdAt":"2022-06-02T10:00:00.000Z"},` +
+				`{"fileId":"file_1","versionInfo":{"id":"v3","name":"Version 3"},"createdAt":"2022-06-03T10:00:00.000Z"},` +
+				`{"fileId":"file_1","versionInfo":{"id":"v1","name":"Version 1"},"createdAt":"2022-06-01T10:00:00.000Z"}]`,
+			count:   3,
+			version: "v3",
+		},
+		"no versions": {
+			body:  `[]`,
+			count: 0,
+			err:   ErrNoVersions,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpTest := iktest.NewHttp(t)
+			ts := httptest.NewServer(httpTest.Handler(200, tc.body))
+			defer ts.Close()
+
+			mediaApi.Config.API.Prefix = ts.URL + "/"
+
+			count, err := mediaApi.VersionCount(ctx, "file_1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count != tc.count {
+				t.Errorf("expected %d versions, got %d", tc.count, count)
+			}
+
+			latest, err := mediaApi.LatestVersion(ctx, "file_1")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if latest.VersionInfo["id"] != tc.version {
+				t.Errorf("expected latest version %q, got %q", tc.version, latest.VersionInfo["id"])
+			}
+
+			httpTest.Test("/files/file_1/versions", "GET", nil)
+		})
+	}
+
+	if _, err := mediaApi.LatestVersion(ctx, ""); err == nil {
+		t.Error("expected error for empty fileId")
+	}
+}
@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagName is the struct tag naming the custom metadata field of a struct field.
+const tagName = "imagekit"
+
+// EncodeCustomMetadata converts a struct with imagekit tags into a custom metadata map, which can
+// be used as CustomMetadata in upload and update params:
+//
+//	type Product struct {
+//		SKU     string  `imagekit:"sku"`
+//		Price   float64 `imagekit:"price,omitempty"`
+//		OnSale  bool    `imagekit:"onSale"`
+//		Comment string  // not sent, fields without the tag are ignored
+//	}
+//
+// The omitempty option skips zero values. v may also be a pointer to such a struct.
+func EncodeCustomMetadata(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("custom metadata struct is nil")
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("custom metadata must be a struct, got %s", rv.Kind())
+	}
+
+	var values = map[string]any{}
+
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		name, omitEmpty, ok := customField(field)
+		if !ok {
+			continue
+		}
+
+		value, err := rv.FieldByIndexErr(field.Index)
+		if err != nil || (omitEmpty && value.IsZero()) {
+			continue
+		}
+		values[name] = value.Interface()
+	}
+
+	return values, nil
+}
+
+// DecodeCustomMetadata stores the custom metadata values into the imagekit tagged fields of the
+// struct pointed to by v. Fields without a value in values are left unchanged.
+func DecodeCustomMetadata(values map[string]any, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("custom metadata target must be a non nil pointer to a struct")
+	}
+	rv = rv.Elem()
+
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		name, _, ok := customField(field)
+		if !ok {
+			continue
+		}
+
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+
+		// the json round trip converts decoded numbers to the type of the field
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		if err = json.Unmarshal(data, rv.FieldByIndex(field.Index).Addr().Interface()); err != nil {
+			return fmt.Errorf("custom metadata field %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// customField returns the custom metadata field name and the omitempty option of an exported
+// struct field with the imagekit tag.
+func customField(field reflect.StructField) (string, bool, bool) {
+	tag, ok := field.Tag.Lookup(tagName)
+	if !ok || tag == "-" || !field.IsExported() || field.Anonymous {
+		return "", false, false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, opts == "omitempty", true
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type product struct {
+	SKU     string  `imagekit:"sku"`
+	Price   float64 `imagekit:"price"`
+	Stock   int     `imagekit:"stock,omitempty"`
+	OnSale  bool    `imagekit:"onSale"`
+	Comment string
+	Skipped string `imagekit:"-"`
+}
+
+func TestCustomMetadata_RoundTrip(t *testing.T) {
+	in := product{SKU: "A-100", Price: 9.5, Stock: 3, OnSale: true, Comment: "local", Skipped: "x"}
+
+	values, err := EncodeCustomMetadata(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{"sku": "A-100", "price": 9.5, "stock": 3, "onSale": true}
+	if !cmp.Equal(values, expected) {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	// values come back from the api decoded as json
+	data, err := json.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	var out product
+	if err = DecodeCustomMetadata(decoded, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	in.Comment, in.Skipped = "", ""
+	if !cmp.Equal(in, out) {
+		t.Errorf("round trip mismatch: %+v", out)
+	}
+
+	if values, err = EncodeCustomMetadata(product{SKU: "B"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := values["stock"]; ok {
+		t.Error("zero stock should be omitted")
+	}
+
+	if _, err = EncodeCustomMetadata("sku"); err == nil {
+		t.Error("expected error for non struct value")
+	}
+
+	if err = DecodeCustomMetadata(decoded, out); err == nil {
+		t.Error("expected error for non pointer target")
+	}
+
+	if err = DecodeCustomMetadata(map[string]any{"price": "free"}, &out); err == nil {
+		t.Error("expected error for mismatched type")
+	}
+}
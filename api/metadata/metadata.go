@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"sync"
 	"time"
 
 	neturl "net/url"
@@ -22,6 +24,14 @@ type API struct {
 	Config config.Configuration
 	Logger *logger.Logger
 	Client api.HttpClient
+
+	// FieldsCacheTTL is how long the custom fields used by ValidateCustomMetadata are cached,
+	// DefaultFieldsCacheTTL when zero.
+	FieldsCacheTTL time.Duration
+
+	fieldsMu      sync.Mutex
+	fields        []CustomField
+	fieldsExpires time.Time
 }
 
 // New creates a new Media API instance from the environment variable.
@@ -38,7 +48,7 @@ func New() (*API, error) {
 func NewFromConfiguration(c *config.Configuration) (*API, error) {
 	return &API{
 		Config: *c,
-		Client: &http.Client{},
+		Client: api.NewClient(c),
 		Logger: logger.New(),
 	}, nil
 }
@@ -166,17 +176,36 @@ type CreateFieldResponse struct {
 
 type UpdateCustomFieldResponse CreateFieldResponse
 
+// UpdateCustomFieldParam represents the updatable attributes of a custom field.
+// Only Label and Schema can be changed; the field Name is immutable once created.
+// An empty Schema is left out of the request and keeps the current schema.
 type UpdateCustomFieldParam struct {
-	Label  string `json:"label"`
+	Label  string `json:"label,omitempty"`
 	Schema Schema `json:"schema"`
 }
 
+// MarshalJSON omits Schema when it is empty.
+func (p UpdateCustomFieldParam) MarshalJSON() ([]byte, error) {
+	type param UpdateCustomFieldParam
+
+	if !reflect.ValueOf(p.Schema).IsZero() {
+		return json.Marshal(param(p))
+	}
+
+	return json.Marshal(struct {
+		Label string `json:"label,omitempty"`
+	}{p.Label})
+}
+
 type CustomFieldsResponse struct {
 	Data []CustomField
 	api.Response
 }
 
 func (m *API) get(ctx context.Context, url string, query map[string]string, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	var err error
 	urlObj, err := neturl.Parse(api.BuildPath(m.Config.API.Prefix, url))
 	if err != nil {
@@ -201,17 +230,19 @@ func (m *API) get(ctx context.Context, url string, query map[string]string, ms a
 		return nil, err
 	}
 
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 
 	return resp, err
 }
 
 func (m *API) post(ctx context.Context, url string, data interface{}, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	url = api.BuildPath(m.Config.API.Prefix, url)
 	var err error
 	var body []byte
@@ -229,16 +260,18 @@ func (m *API) post(ctx context.Context, url string, data interface{}, ms api.Met
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 	return resp, err
 }
 
 func (m *API) patch(ctx context.Context, url string, data interface{}, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	url = api.BuildPath(m.Config.API.Prefix, url)
 	var err error
 	var body []byte
@@ -256,16 +289,18 @@ func (m *API) patch(ctx context.Context, url string, data interface{}, ms api.Me
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 	return resp, err
 }
 
 func (m *API) delete(ctx context.Context, url string, ms api.MetaSetter) (*http.Response, error) {
+	ctx, cancel := api.ContextWithTimeout(ctx, m.Config.API.EffectiveTimeout())
+	defer cancel()
+
 	var err error
 	url = api.BuildPath(m.Config.API.Prefix, url)
 
@@ -275,12 +310,11 @@ func (m *API) delete(ctx context.Context, url string, ms api.MetaSetter) (*http.
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	req.SetBasicAuth(m.Config.Cloud.PrivateKey, "")
-
-	resp, err := m.Client.Do(req.WithContext(ctx))
+	resp, err := api.Do(ctx, m.Client, &m.Config, req)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, ms)
+	meta := api.SetResponseMeta(resp, ms)
+	m.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 	return resp, err
 }
 
@@ -307,18 +341,15 @@ func (m *API) FromFile(ctx context.Context, fileId string) (*MetadataResponse, e
 	return response, err
 }
 
+// FromUrl fetches metadata of the image at url, which may be any url served by ImageKit, including
+// transformed ones
 func (m *API) FromUrl(ctx context.Context, url string) (*MetadataResponse, error) {
-	var err error
 	if url == "" {
 		return nil, errors.New("url can not be blank")
 	}
 
 	var response = &MetadataResponse{}
 
-	if err != nil {
-		return nil, err
-	}
-
 	resp, err := m.get(ctx, "metadata", map[string]string{"url": url}, response)
 
 	if err != nil {
@@ -334,11 +365,15 @@ func (m *API) FromUrl(ctx context.Context, url string) (*MetadataResponse, error
 	return response, err
 }
 
-// CreateCustomField creates new custom metadata field
+// CreateCustomField creates new custom metadata field. The schema is validated against the rules of its type before the request is made.
 func (m *API) CreateCustomField(ctx context.Context, param CreateFieldParam) (*CreateFieldResponse, error) {
 	var err error
 	var response = &CreateFieldResponse{}
 
+	if err = param.Schema.Validate(); err != nil {
+		return nil, err
+	}
+
 	resp, err := m.post(ctx, "customMetadataFields", param, response)
 
 	if err != nil {
@@ -348,6 +383,7 @@ func (m *API) CreateCustomField(ctx context.Context, param CreateFieldParam) (*C
 	if resp.StatusCode != 201 {
 		err = response.ParseError()
 	} else {
+		m.InvalidateFieldsCache()
 		err = json.Unmarshal(response.Body(), &response.Data)
 	}
 
@@ -381,11 +417,19 @@ func (m *API) CustomFields(ctx context.Context, includeDeleted bool) (*CustomFie
 	return response, err
 }
 
-// UpdateCustomField updates label or schema attributes of given custom field id
+// UpdateCustomField updates label or schema attributes of given custom field id. Only the attributes set in param are sent.
 func (m *API) UpdateCustomField(ctx context.Context, fieldId string, param UpdateCustomFieldParam) (*UpdateCustomFieldResponse, error) {
 	var err error
 	var response = &UpdateCustomFieldResponse{}
 
+	if fieldId == "" {
+		return nil, errors.New("fieldId can not be blank")
+	}
+
+	if param.Label == "" && reflect.ValueOf(param.Schema).IsZero() {
+		return nil, errors.New("label or schema is required")
+	}
+
 	if err = validator.Validate(&param); err != nil {
 		return nil, err
 	}
@@ -399,6 +443,7 @@ func (m *API) UpdateCustomField(ctx context.Context, fieldId string, param Updat
 	if resp.StatusCode != 200 {
 		err = response.ParseError()
 	} else {
+		m.InvalidateFieldsCache()
 		err = json.Unmarshal(response.Body(), &response.Data)
 	}
 
@@ -421,6 +466,8 @@ func (m *API) DeleteCustomField(ctx context.Context, fieldId string) (*api.Respo
 
 	if resp.StatusCode != 204 {
 		err = response.ParseError()
+	} else {
+		m.InvalidateFieldsCache()
 	}
 
 	return response, err
@@ -114,9 +114,6 @@ func TestMetadata_FromUrl(t *testing.T) {
 		t.Error("expected error")
 	}
 
-	if err == nil {
-		t.Error("expected error")
-	}
 	if err = json.Unmarshal([]byte(respBody), respObj); err != nil {
 		t.Error(err)
 	}
@@ -188,6 +185,14 @@ func TestMetadata_CreateCustomField(t *testing.T) {
 
 	httpTest.Test("/customMetadataFields", "POST", param)
 
+	if _, err = metadataApi.CreateCustomField(ctx, CreateFieldParam{
+		Name:   "country",
+		Label:  "Country",
+		Schema: Schema{Type: SingleSelect},
+	}); err == nil {
+		t.Error("expected schema validation error")
+	}
+
 	errServer := iktest.NewErrorServer(t)
 	metadataApi.Config.API.Prefix = errServer.Url() + "/"
 
@@ -268,6 +273,30 @@ func TestMetadata_UpdateCustomField(t *testing.T) {
 
 	httpTest.Test("/customMetadataFields/file_id", "PATCH", param)
 
+	if string(httpTest.Body) != `{"label":"Cost"}` {
+		t.Errorf("unexpected body: %s", httpTest.Body)
+	}
+
+	if _, err = metadataApi.UpdateCustomField(ctx, "file_id", UpdateCustomFieldParam{}); err == nil {
+		t.Error("expected error when neither label nor schema is set")
+	}
+
+	param = UpdateCustomFieldParam{
+		Schema: Schema{MinValue: 100, MaxValue: 300},
+	}
+
+	if _, err = metadataApi.UpdateCustomField(ctx, "file_id", param); err != nil {
+		t.Error(err)
+	}
+
+	if string(httpTest.Body) != `{"schema":{"type":"","minValue":100,"maxValue":300}}` {
+		t.Errorf("unexpected body: %s", httpTest.Body)
+	}
+
+	if _, err = metadataApi.UpdateCustomField(ctx, "", param); err == nil {
+		t.Error("expected error for blank fieldId")
+	}
+
 	errServer := iktest.NewErrorServer(t)
 	metadataApi.Config.API.Prefix = errServer.Url() + "/"
 
@@ -0,0 +1,180 @@
+package metadata
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Custom metadata field schema types.
+const (
+	Text         = "Text"
+	Textarea     = "Textarea"
+	Number       = "Number"
+	Date         = "Date"
+	Boolean      = "Boolean"
+	SingleSelect = "SingleSelect"
+	MultiSelect  = "MultiSelect"
+)
+
+// SchemaError is returned when a custom metadata field schema is invalid for its type.
+type SchemaError struct {
+	Field   string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("schema.%s: %s", e.Field, e.Message)
+}
+
+// Validate checks schema attributes against the rules of its type.
+func (s Schema) Validate() error {
+	switch s.Type {
+	case Text, Textarea:
+		if err := s.forbid("minValue", s.MinValue, "maxValue", s.MaxValue, "selectOptions", s.SelectOptions); err != nil {
+			return err
+		}
+		return s.validateLength()
+	case Number:
+		if err := s.forbidLength(); err != nil {
+			return err
+		}
+		if err := s.forbid("selectOptions", s.SelectOptions); err != nil {
+			return err
+		}
+		return s.validateNumberRange()
+	case Date:
+		if err := s.forbidLength(); err != nil {
+			return err
+		}
+		if err := s.forbid("selectOptions", s.SelectOptions); err != nil {
+			return err
+		}
+		return s.validateDateRange()
+	case Boolean:
+		if err := s.forbidLength(); err != nil {
+			return err
+		}
+		return s.forbid("minValue", s.MinValue, "maxValue", s.MaxValue, "selectOptions", s.SelectOptions)
+	case SingleSelect, MultiSelect:
+		if err := s.forbidLength(); err != nil {
+			return err
+		}
+		if err := s.forbid("minValue", s.MinValue, "maxValue", s.MaxValue); err != nil {
+			return err
+		}
+		opts := reflect.ValueOf(s.SelectOptions)
+		if s.SelectOptions == nil || (opts.Kind() != reflect.Slice && opts.Kind() != reflect.Array) || opts.Len() == 0 {
+			return &SchemaError{"selectOptions", "is required for " + s.Type}
+		}
+		return nil
+	case "":
+		return &SchemaError{"type", "is required"}
+	default:
+		return &SchemaError{"type", fmt.Sprintf("unsupported type %q", s.Type)}
+	}
+}
+
+// forbid takes name, value pairs and returns an error for the first non nil value.
+func (s Schema) forbid(pairs ...interface{}) error {
+	for i := 0; i < len(pairs); i += 2 {
+		if pairs[i+1] != nil {
+			return &SchemaError{pairs[i].(string), "is not allowed for " + s.Type}
+		}
+	}
+	return nil
+}
+
+func (s Schema) forbidLength() error {
+	if s.MinLength != 0 {
+		return &SchemaError{"minLength", "is not allowed for " + s.Type}
+	}
+	if s.MaxLength != 0 {
+		return &SchemaError{"maxLength", "is not allowed for " + s.Type}
+	}
+	return nil
+}
+
+func (s Schema) validateLength() error {
+	if s.MinLength < 0 {
+		return &SchemaError{"minLength", "can not be negative"}
+	}
+	if s.MaxLength < 0 {
+		return &SchemaError{"maxLength", "can not be negative"}
+	}
+	if s.MaxLength != 0 && s.MinLength > s.MaxLength {
+		return &SchemaError{"minLength", "can not be greater than maxLength"}
+	}
+	return nil
+}
+
+func (s Schema) validateNumberRange() error {
+	var min, max float64
+	var ok bool
+
+	if s.MinValue != nil {
+		if min, ok = toFloat(s.MinValue); !ok {
+			return &SchemaError{"minValue", "must be a number"}
+		}
+	}
+
+	if s.MaxValue != nil {
+		if max, ok = toFloat(s.MaxValue); !ok {
+			return &SchemaError{"maxValue", "must be a number"}
+		}
+	}
+
+	if s.MinValue != nil && s.MaxValue != nil && min > max {
+		return &SchemaError{"minValue", "can not be greater than maxValue"}
+	}
+	return nil
+}
+
+func (s Schema) validateDateRange() error {
+	var min, max time.Time
+	var err error
+
+	if s.MinValue != nil {
+		if min, err = toDate(s.MinValue); err != nil {
+			return &SchemaError{"minValue", "must be an ISO8601 date"}
+		}
+	}
+
+	if s.MaxValue != nil {
+		if max, err = toDate(s.MaxValue); err != nil {
+			return &SchemaError{"maxValue", "must be an ISO8601 date"}
+		}
+	}
+
+	if s.MinValue != nil && s.MaxValue != nil && min.After(max) {
+		return &SchemaError{"minValue", "can not be after maxValue"}
+	}
+	return nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func toDate(v interface{}) (time.Time, error) {
+	switch d := v.(type) {
+	case time.Time:
+		return d, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339, d); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", d)
+	}
+	return time.Time{}, fmt.Errorf("invalid date %v", v)
+}
@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	var cases = map[string]struct {
+		schema Schema
+		field  string
+	}{
+		"text valid": {
+			schema: Schema{Type: Text, MinLength: 1, MaxLength: 10, DefaultValue: "abc"},
+		},
+		"text min greater than max": {
+			schema: Schema{Type: Text, MinLength: 10, MaxLength: 1},
+			field:  "minLength",
+		},
+		"textarea with min value": {
+			schema: Schema{Type: Textarea, MinValue: 1},
+			field:  "minValue",
+		},
+		"number valid": {
+			schema: Schema{Type: Number, MinValue: 1, MaxValue: 120.5},
+		},
+		"number min greater than max": {
+			schema: Schema{Type: Number, MinValue: 100, MaxValue: 1},
+			field:  "minValue",
+		},
+		"number with string max": {
+			schema: Schema{Type: Number, MaxValue: "100"},
+			field:  "maxValue",
+		},
+		"number with max length": {
+			schema: Schema{Type: Number, MaxLength: 10},
+			field:  "maxLength",
+		},
+		"date valid": {
+			schema: Schema{Type: Date, MinValue: "2022-01-01T00:00:00Z", MaxValue: "2022-12-31"},
+		},
+		"date invalid": {
+			schema: Schema{Type: Date, MinValue: "yesterday"},
+			field:  "minValue",
+		},
+		"date min after max": {
+			schema: Schema{Type: Date, MinValue: "2023-01-01", MaxValue: "2022-01-01"},
+			field:  "minValue",
+		},
+		"boolean valid": {
+			schema: Schema{Type: Boolean, DefaultValue: true},
+		},
+		"boolean with select options": {
+			schema: Schema{Type: Boolean, SelectOptions: []string{"yes"}},
+			field:  "selectOptions",
+		},
+		"single select valid": {
+			schema: Schema{Type: SingleSelect, SelectOptions: []string{"USA", "Canada"}},
+		},
+		"single select without options": {
+			schema: Schema{Type: SingleSelect},
+			field:  "selectOptions",
+		},
+		"multi select valid": {
+			schema: Schema{Type: MultiSelect, SelectOptions: []any{"one", 2}},
+		},
+		"multi select empty options": {
+			schema: Schema{Type: MultiSelect, SelectOptions: []string{}},
+			field:  "selectOptions",
+		},
+		"multi select with min length": {
+			schema: Schema{Type: MultiSelect, SelectOptions: []string{"one"}, MinLength: 1},
+			field:  "minLength",
+		},
+		"missing type": {
+			schema: Schema{},
+			field:  "type",
+		},
+		"unknown type": {
+			schema: Schema{Type: "Color"},
+			field:  "type",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.schema.Validate()
+
+			if tc.field == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected SchemaError, got: %v", err)
+			}
+
+			if schemaErr.Field != tc.field {
+				t.Errorf("expected field: %s, got: %s", tc.field, schemaErr.Field)
+			}
+		})
+	}
+}
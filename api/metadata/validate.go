@@ -0,0 +1,187 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultFieldsCacheTTL is the default time the custom fields used by ValidateCustomMetadata are
+// cached.
+const DefaultFieldsCacheTTL = 5 * time.Minute
+
+// ValueError is returned by ValidateCustomMetadata when a value does not match the schema of its
+// custom field.
+type ValueError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("customMetadata.%s: %s", e.Field, e.Message)
+}
+
+// CachedCustomFields returns the custom metadata fields, excluding deleted ones. The fields are
+// fetched once and cached for FieldsCacheTTL. Creating, updating or deleting a field through m
+// invalidates the cache.
+func (m *API) CachedCustomFields(ctx context.Context) ([]CustomField, error) {
+	m.fieldsMu.Lock()
+	defer m.fieldsMu.Unlock()
+
+	if m.fields != nil && time.Now().Before(m.fieldsExpires) {
+		return m.fields, nil
+	}
+
+	resp, err := m.CustomFields(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := m.FieldsCacheTTL
+	if ttl == 0 {
+		ttl = DefaultFieldsCacheTTL
+	}
+
+	m.fields = resp.Data
+	if m.fields == nil {
+		m.fields = []CustomField{}
+	}
+	m.fieldsExpires = time.Now().Add(ttl)
+
+	return m.fields, nil
+}
+
+// InvalidateFieldsCache removes the cached custom fields so the next CachedCustomFields call
+// fetches them again.
+func (m *API) InvalidateFieldsCache() {
+	m.fieldsMu.Lock()
+	defer m.fieldsMu.Unlock()
+
+	m.fields = nil
+}
+
+// ValidateCustomMetadata checks values against the schema of the cached custom fields before they
+// are sent with an upload or update. It rejects unknown fields, values of the wrong type, values
+// outside the length or range of the schema and options which are not allowed. Required fields
+// are not checked, as updates may set only some of the fields.
+func (m *API) ValidateCustomMetadata(ctx context.Context, values map[string]interface{}) error {
+	fields, err := m.CachedCustomFields(ctx)
+	if err != nil {
+		return err
+	}
+
+	return validateValues(fields, values)
+}
+
+func validateValues(fields []CustomField, values map[string]interface{}) error {
+	var schemas = make(map[string]Schema, len(fields))
+	for _, f := range fields {
+		schemas[f.Name] = f.Schema
+	}
+
+	for name, value := range values {
+		schema, ok := schemas[name]
+		if !ok {
+			return &ValueError{name, "is not a custom metadata field"}
+		}
+
+		if msg := schema.check(value); msg != "" {
+			return &ValueError{name, msg}
+		}
+	}
+
+	return nil
+}
+
+// check returns why value does not match the schema, or an empty string.
+func (s Schema) check(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	switch s.Type {
+	case Text, Textarea:
+		str, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != 0 && n < s.MinLength {
+			return fmt.Sprintf("must be at least %d characters long", s.MinLength)
+		}
+		if s.MaxLength != 0 && n > s.MaxLength {
+			return fmt.Sprintf("must be at most %d characters long", s.MaxLength)
+		}
+	case Number:
+		n, ok := toFloat(value)
+		if !ok {
+			return "must be a number"
+		}
+		if min, ok := toFloat(s.MinValue); ok && n < min {
+			return fmt.Sprintf("must be at least %v", s.MinValue)
+		}
+		if max, ok := toFloat(s.MaxValue); ok && n > max {
+			return fmt.Sprintf("must be at most %v", s.MaxValue)
+		}
+	case Date:
+		d, err := toDate(value)
+		if err != nil {
+			return "must be an ISO8601 date"
+		}
+		if min, err := toDate(s.MinValue); err == nil && d.Before(min) {
+			return fmt.Sprintf("can not be before %v", s.MinValue)
+		}
+		if max, err := toDate(s.MaxValue); err == nil && d.After(max) {
+			return fmt.Sprintf("can not be after %v", s.MaxValue)
+		}
+	case Boolean:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case SingleSelect:
+		if !s.allows(value) {
+			return fmt.Sprintf("%v is not one of the select options", value)
+		}
+	case MultiSelect:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return "must be a list of select options"
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if option := rv.Index(i).Interface(); !s.allows(option) {
+				return fmt.Sprintf("%v is not one of the select options", option)
+			}
+		}
+	}
+
+	return ""
+}
+
+// allows reports whether value is one of the select options. Numbers are compared by value, as
+// options decoded from json are float64.
+func (s Schema) allows(value interface{}) bool {
+	opts := reflect.ValueOf(s.SelectOptions)
+	if opts.Kind() != reflect.Slice && opts.Kind() != reflect.Array {
+		return false
+	}
+
+	n, isNumber := toFloat(value)
+
+	for i := 0; i < opts.Len(); i++ {
+		option := opts.Index(i).Interface()
+
+		if isNumber {
+			if o, ok := toFloat(option); ok && o == n {
+				return true
+			}
+			continue
+		}
+
+		if reflect.DeepEqual(option, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package metadata
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	iktest "github.com/imagekit-developer/imagekit-go/test"
+)
+
+const schemaBody = `[{"id":"1","name":"price","label":"Price","schema":{"type":"Number","minValue":1,"maxValue":1000}},` +
+	`{"id":"2","name":"country","label":"Country","schema":{"type":"SingleSelect","selectOptions":["USA","Canada"]}},` +
+	`{"id":"3","name":"sizes","label":"Sizes","schema":{"type":"MultiSelect","selectOptions":[1,2,3]}},` +
+	`{"id":"4","name":"title","label":"Title","schema":{"type":"Text","minLength":2,"maxLength":5}},` +
+	`{"id":"5","name":"released","label":"Released","schema":{"type":"Date","minValue":"2020-01-01"}},` +
+	`{"id":"6","name":"public","label":"Public","schema":{"type":"Boolean"}}]`
+
+func TestMetadata_ValidateCustomMetadata(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(schemaBody))
+	}))
+	defer ts.Close()
+
+	m, err := NewFromConfiguration(iktest.Cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Config.API.Prefix = ts.URL + "/"
+
+	valid := map[string]interface{}{
+		"price":    99.5,
+		"country":  "Canada",
+		"sizes":    []int{1, 3},
+		"title":    "Shoe",
+		"released": "2022-06-01T00:00:00Z",
+		"public":   true,
+	}
+
+	if err = m.ValidateCustomMetadata(ctx, valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var invalid = map[string]map[string]interface{}{
+		"unknown field":  {"weight": 1},
+		"not a number":   {"price": "99"},
+		"below minimum":  {"price": 0},
+		"above maximum":  {"price": 1001},
+		"unknown option": {"country": "Mexico"},
+		"multi not list": {"sizes": 1},
+		"multi option":   {"sizes": []interface{}{1, 4}},
+		"too short":      {"title": "a"},
+		"too long":       {"title": "sneaker"},
+		"bad date":       {"released": "yesterday"},
+		"early date":     {"released": "2019-12-31"},
+		"not boolean":    {"public": "yes"},
+	}
+
+	for name, values := range invalid {
+		err := m.ValidateCustomMetadata(ctx, values)
+
+		var valueErr *ValueError
+		if !errors.As(err, &valueErr) {
+			t.Errorf("%s: expected ValueError, got %v", name, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected fields to be fetched once, got %d requests", n)
+	}
+
+	m.InvalidateFieldsCache()
+	if err = m.ValidateCustomMetadata(ctx, valid); err != nil {
+		t.Fatal(err)
+	}
+
+	m.FieldsCacheTTL = time.Nanosecond
+	m.InvalidateFieldsCache()
+	_ = m.ValidateCustomMetadata(ctx, valid)
+	time.Sleep(time.Millisecond)
+	_ = m.ValidateCustomMetadata(ctx, valid)
+
+	if n := atomic.LoadInt32(&calls); n != 4 {
+		t.Errorf("expected cache to be refreshed, got %d requests", n)
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/imagekit-developer/imagekit-go/config"
+)
+
+// NewClient returns the client set with config.WithHTTPClient, or a new http.Client.
+func NewClient(cfg *config.Configuration) HttpClient {
+	if cfg.API.HTTPClient != nil {
+		return cfg.API.HTTPClient
+	}
+	return &http.Client{}
+}
+
+// Do sends req with client after adding the configured headers and authorization. Failed requests
+// are retried as configured by MaxRetries, see config.WithRetries. While the configured circuit
+// breaker is open, ErrCircuitOpen is returned without sending req. A nil client falls back to
+// http.DefaultClient.
+func Do(ctx context.Context, client HttpClient, cfg *config.Configuration, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	breaker := cfg.API.CircuitBreaker
+	if breaker == nil {
+		return do(ctx, client, cfg, req)
+	}
+
+	if !breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := do(ctx, client, cfg, req)
+
+	// canceled requests tell nothing about the health of the api
+	if ctx.Err() == nil {
+		breaker.Record(err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	} else {
+		breaker.Release()
+	}
+
+	return resp, err
+}
+
+func do(ctx context.Context, client HttpClient, cfg *config.Configuration, req *http.Request) (*http.Response, error) {
+	if cfg.API.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.API.UserAgent)
+	}
+
+	for key, values := range cfg.API.Headers {
+		key = http.CanonicalHeaderKey(key)
+
+		// headers set by the sdk and the authorization always take precedence
+		if key == "Authorization" || req.Header.Get(key) != "" {
+			continue
+		}
+
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+
+	if cfg.API.Authorization != "" {
+		req.Header.Set("Authorization", cfg.API.Authorization)
+	} else {
+		req.SetBasicAuth(cfg.Cloud.PrivateKey, "")
+	}
+
+	ctx = withVerboseRequest(ctx, cfg, req)
+
+	resp, err := sendWithFailover(ctx, client, cfg, req)
+
+	for attempt := 1; attempt <= cfg.API.MaxRetries && ctx.Err() == nil && retryable(cfg, req, resp, err); attempt++ {
+		retry, rerr := rebase(ctx, req, "", "")
+		if rerr != nil {
+			break
+		}
+
+		delay := retryDelay(cfg, attempt, resp)
+		DeferredBodyClose(resp)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		resp, err = sendWithFailover(ctx, client, cfg, retry)
+	}
+
+	return resp, err
+}
+
+// sendWithFailover sends req and on a connection error tries the failover prefixes of its url.
+func sendWithFailover(ctx context.Context, client HttpClient, cfg *config.Configuration, req *http.Request) (*http.Response, error) {
+	resp, err := send(client, cfg, req.WithContext(ctx))
+	if err == nil || ctx.Err() != nil {
+		return resp, err
+	}
+
+	prefix, failover := failoverPrefixes(cfg, req.URL.String())
+
+	for _, next := range failover {
+		retry, rerr := rebase(ctx, req, prefix, next)
+		if rerr != nil {
+			break
+		}
+
+		if resp, err = send(client, cfg, retry); err == nil || ctx.Err() != nil {
+			return resp, err
+		}
+	}
+
+	return resp, err
+}
+
+// send passes req to the configured RequestMutator and sends it with client.
+func send(client HttpClient, cfg *config.Configuration, req *http.Request) (*http.Response, error) {
+	if cfg.API.RequestMutator != nil {
+		if err := cfg.API.RequestMutator(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return client.Do(req)
+}
+
+// failoverPrefixes returns the configured prefix of url and its failover alternatives.
+func failoverPrefixes(cfg *config.Configuration, url string) (string, []string) {
+	if cfg.API.Prefix != "" && strings.HasPrefix(url, cfg.API.Prefix) {
+		return cfg.API.Prefix, cfg.API.Failover
+	}
+
+	if cfg.API.UploadPrefix != "" && strings.HasPrefix(url, cfg.API.UploadPrefix) {
+		return cfg.API.UploadPrefix, cfg.API.UploadFailover
+	}
+
+	return "", nil
+}
+
+// rebase clones req with its url moved from prefix to next. It fails when the body can not be
+// read again.
+func rebase(ctx context.Context, req *http.Request, prefix string, next string) (*http.Request, error) {
+	u, err := url.Parse(next + strings.TrimPrefix(req.URL.String(), prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(ctx)
+	retry.URL = u
+	retry.Host = ""
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errors.New("request body can not be replayed")
+		}
+
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return retry, nil
+}
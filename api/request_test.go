@@ -0,0 +1,555 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/imagekit-developer/imagekit-go/config"
+)
+
+// MockedClient records the last request and returns an empty 200 response.
+type MockedClient struct {
+	Req *http.Request
+}
+
+func (c *MockedClient) Do(req *http.Request) (*http.Response, error) {
+	c.Req = req
+	return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func Test_DoHeaders(t *testing.T) {
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithHeaders(http.Header{
+			"X-Gateway-Token": []string{"Bearer gw-token"},
+			"Authorization":   []string{"Bearer other"},
+			"Content-Type":    []string{"text/plain"},
+		}),
+	)
+
+	client := &MockedClient{}
+	req, _ := http.NewRequest(http.MethodPost, "https://api.imagekit.io/v1/files", nil)
+	req.Header.Set("Content-Type", "application/json")
+
+	if _, err := Do(context.Background(), client, cfg, req); err != nil {
+		t.Fatal(err)
+	}
+
+	h := client.Req.Header
+
+	if h.Get("X-Gateway-Token") != "Bearer gw-token" {
+		t.Error("extra header missing")
+	}
+
+	if h.Get("Authorization") != "Basic cHJpdmF0ZV86" {
+		t.Errorf("authorization overridden: %s", h.Get("Authorization"))
+	}
+
+	if h.Get("Content-Type") != "application/json" {
+		t.Errorf("content type overridden: %s", h.Get("Content-Type"))
+	}
+
+	config.WithAuthorization("Bearer other")(cfg)
+	req, _ = http.NewRequest(http.MethodGet, "https://api.imagekit.io/v1/files", nil)
+
+	if _, err := Do(context.Background(), client, cfg, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Req.Header.Get("Authorization") != "Bearer other" {
+		t.Errorf("expected explicit authorization, got: %s", client.Req.Header.Get("Authorization"))
+	}
+}
+
+func Test_DoUserAgent(t *testing.T) {
+	var cases = map[string]struct {
+		opts     []config.Option
+		expected string
+	}{
+		"default":      {nil, DefaultUserAgent},
+		"headers":      {[]config.Option{config.WithHeaders(http.Header{"User-Agent": []string{"gateway"}})}, "gateway"},
+		"option":       {[]config.Option{config.WithUserAgent("app/1.0")}, "app/1.0"},
+		"option first": {[]config.Option{config.WithHeaders(http.Header{"User-Agent": []string{"gateway"}}), config.WithUserAgent("app/1.0")}, "app/1.0"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/", tc.opts...)
+
+			client := &MockedClient{}
+			req, _ := http.NewRequest(http.MethodGet, "https://api.imagekit.io/v1/files", nil)
+
+			if _, err := Do(context.Background(), client, cfg, req); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := client.Req.Header.Get("User-Agent"); got != tc.expected {
+				t.Errorf("expected User-Agent %q, got %q", tc.expected, got)
+			}
+
+			if client.Req.Header.Get("Authorization") != "Basic cHJpdmF0ZV86" {
+				t.Errorf("authorization overridden: %s", client.Req.Header.Get("Authorization"))
+			}
+		})
+	}
+
+	if !strings.HasPrefix(DefaultUserAgent, "imagekit-go") {
+		t.Errorf("unexpected default User-Agent %q", DefaultUserAgent)
+	}
+}
+
+func Test_DoDefaultClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/")
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/files", nil)
+
+	resp, err := Do(context.Background(), nil, cfg, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+func Test_DoFailover(t *testing.T) {
+	var calls []string
+
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		w.WriteHeader(200)
+	}))
+	defer secondary.Close()
+
+	// nothing listens on the closed server's address
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithFailover(secondary.URL+"/v1/"),
+	)
+	cfg.API.Prefix = unreachable.URL + "/v1/"
+
+	req, _ := http.NewRequest(http.MethodPost, cfg.API.Prefix+"files/addTags", bytes.NewBufferString(`{"tags":["a"]}`))
+
+	resp, err := Do(context.Background(), http.DefaultClient, cfg, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(calls) != 1 || calls[0] != `POST /v1/files/addTags {"tags":["a"]}` {
+		t.Errorf("unexpected failover calls: %v", calls)
+	}
+
+	calls = nil
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer primary.Close()
+
+	cfg.API.Prefix = primary.URL + "/v1/"
+	req, _ = http.NewRequest(http.MethodGet, cfg.API.Prefix+"files/123/details", nil)
+
+	if resp, err = Do(context.Background(), http.DefaultClient, cfg, req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 404 || len(calls) != 0 {
+		t.Errorf("4xx response should not fail over, got status %d and calls %v", resp.StatusCode, calls)
+	}
+}
+
+func Test_DoRequestMutator(t *testing.T) {
+	var abort = errors.New("proxy signature unavailable")
+	var fail bool
+
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithRequestMutator(func(req *http.Request) error {
+			if fail {
+				return abort
+			}
+			req.Header.Set("X-Proxy-Signature", req.Method+" "+req.URL.Path+" "+req.Header.Get("Authorization"))
+			return nil
+		}),
+	)
+
+	client := &MockedClient{}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.imagekit.io/v1/files", nil)
+
+	if _, err := Do(context.Background(), client, cfg, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if sig := client.Req.Header.Get("X-Proxy-Signature"); sig != "GET /v1/files Basic cHJpdmF0ZV86" {
+		t.Errorf("mutator did not run after authorization: %q", sig)
+	}
+
+	fail = true
+	client.Req = nil
+	req, _ = http.NewRequest(http.MethodGet, "https://api.imagekit.io/v1/files", nil)
+
+	if _, err := Do(context.Background(), client, cfg, req); !errors.Is(err, abort) {
+		t.Errorf("expected mutator error, got %v", err)
+	}
+
+	if client.Req != nil {
+		t.Error("request should not be sent when the mutator fails")
+	}
+}
+
+func Test_DoRetry(t *testing.T) {
+	var calls []string
+	var failures int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+string(body))
+
+		if failures > 0 {
+			failures--
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	// the delay would time out the test if Retry-After was ignored
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithRetries(2, time.Hour, time.Hour))
+
+	var cases = map[string]struct {
+		method     string
+		failures   int
+		nonIdem    bool
+		status     int
+		calls      int
+		replayBody bool
+	}{
+		"get recovers":          {http.MethodGet, 2, false, 200, 3, false},
+		"get gives up":          {http.MethodGet, 3, false, 503, 3, false},
+		"post not retried":      {http.MethodPost, 1, false, 503, 1, false},
+		"post retried when set": {http.MethodPost, 1, true, 200, 2, true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls, failures = nil, tc.failures
+			cfg.API.RetryNonIdempotent = tc.nonIdem
+
+			req, _ := http.NewRequest(tc.method, ts.URL+"/files", bytes.NewBufferString("payload"))
+
+			resp, err := Do(context.Background(), http.DefaultClient, cfg, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.status || len(calls) != tc.calls {
+				t.Errorf("expected status %d after %d calls, got %d after %v", tc.status, tc.calls, resp.StatusCode, calls)
+			}
+
+			if tc.replayBody && calls[len(calls)-1] != "POST payload" {
+				t.Errorf("body not replayed: %v", calls)
+			}
+		})
+	}
+
+	// without Retry-After the backoff delay applies and is interrupted by ctx
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/files", nil)
+	if _, err := Do(ctx, http.DefaultClient, cfg, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func Test_DoRetryPolicy(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("X-Transient", "1")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithRetries(3, time.Millisecond, time.Millisecond),
+		config.WithRetryPolicy(func(resp *http.Response, err error) bool {
+			return err == nil && resp.Header.Get("X-Transient") != ""
+		}))
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/files", nil)
+
+	resp, err := Do(context.Background(), http.DefaultClient, cfg, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// the 400 is retried, the 503 is not retryable under the custom policy
+	if calls != 2 || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 after 2 calls, got %d after %d", resp.StatusCode, calls)
+	}
+
+	if !DefaultRetryPolicy(nil, errors.New("connection reset")) || DefaultRetryPolicy(&http.Response{StatusCode: 400}, nil) {
+		t.Error("unexpected default policy")
+	}
+}
+
+func Test_RetryDelay(t *testing.T) {
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithRetries(5, 100*time.Millisecond, time.Second))
+
+	var bounds = map[int][2]time.Duration{
+		1: {50 * time.Millisecond, 100 * time.Millisecond},
+		2: {100 * time.Millisecond, 200 * time.Millisecond},
+		3: {200 * time.Millisecond, 400 * time.Millisecond},
+		5: {500 * time.Millisecond, time.Second},
+		9: {500 * time.Millisecond, time.Second},
+	}
+
+	for attempt, bound := range bounds {
+		for i := 0; i < 20; i++ {
+			if d := retryDelay(cfg, attempt, nil); d < bound[0] || d > bound[1] {
+				t.Errorf("attempt %d: delay %s out of range %v", attempt, d, bound)
+			}
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"1"}}}
+	if d := retryDelay(cfg, 1, resp); d != time.Second {
+		t.Errorf("expected Retry-After delay, got %s", d)
+	}
+
+	// a server asking for longer than RetryMaxDelay can not stall the retries
+	resp.Header.Set("Retry-After", "3600")
+	if d := retryDelay(cfg, 1, resp); d != time.Second {
+		t.Errorf("expected Retry-After capped at RetryMaxDelay, got %s", d)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if d := retryDelay(cfg, 1, resp); d != time.Second {
+		t.Errorf("expected Retry-After date capped at RetryMaxDelay, got %s", d)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if d := retryDelay(cfg, 1, resp); d != 0 {
+		t.Errorf("expected no delay for past Retry-After date, got %s", d)
+	}
+}
+
+func Test_VerboseErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"Invalid tags","help":"tags must be an array"}`))
+	}))
+	defer ts.Close()
+
+	for _, verbose := range []bool{false, true} {
+		cfg := config.NewFromParams("private_key_value", "public_", "https://ik.imagekit.io/test/",
+			config.WithVerboseErrors(verbose))
+
+		body := `{"tags":"sale","token":"client-token","note":"private_key_value"}`
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/files/addTags", bytes.NewBufferString(body))
+
+		resp, err := Do(context.Background(), http.DefaultClient, cfg, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		response := &Response{}
+		SetResponseMeta(resp, response)
+		msg := response.ParseError().Error()
+
+		if !verbose {
+			if msg != "Invalid tags" {
+				t.Errorf("unexpected error without verbose errors: %q", msg)
+			}
+			continue
+		}
+
+		for _, expected := range []string{
+			"Invalid tags\nrequest: POST " + ts.URL + "/files/addTags",
+			`{"tags":"sale","token":"****","note":"****"}`,
+			"response: 400 Bad Request",
+			`"help":"tags must be an array"`,
+		} {
+			if !strings.Contains(msg, expected) {
+				t.Errorf("verbose error does not contain %q:\n%s", expected, msg)
+			}
+		}
+
+		if strings.Contains(msg, "client-token") || strings.Contains(msg, "private_key_value") {
+			t.Errorf("credentials not redacted:\n%s", msg)
+		}
+	}
+}
+
+func Test_DoCircuitBreaker(t *testing.T) {
+	var calls int
+	var status = http.StatusInternalServerError
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithCircuitBreaker(2, 50*time.Millisecond))
+
+	get := func() (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/files", nil)
+		resp, err := Do(context.Background(), http.DefaultClient, cfg, req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := get(); !errors.Is(err, ErrCircuitOpen) || calls != 2 {
+		t.Fatalf("expected open circuit after 2 calls, got %v after %d calls", err, calls)
+	}
+
+	// after the cooldown a failing trial opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := get(); err != nil || calls != 3 {
+		t.Fatalf("expected trial request, got %v after %d calls", err, calls)
+	}
+
+	if _, err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit after failed trial, got %v", err)
+	}
+
+	// a successful trial closes it
+	time.Sleep(60 * time.Millisecond)
+	status = http.StatusNotFound
+
+	for i := 0; i < 3; i++ {
+		if resp, err := get(); err != nil || resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected closed circuit, got %v", err)
+		}
+	}
+
+	if calls != 6 {
+		t.Errorf("expected 6 calls, got %d", calls)
+	}
+}
+
+func Test_DoCircuitBreakerHalfOpen(t *testing.T) {
+	var calls int32
+	var release = make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			<-release
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	cfg := config.NewFromParams("private_", "public_", "https://ik.imagekit.io/test/",
+		config.WithCircuitBreaker(1, 20*time.Millisecond))
+
+	get := func(ctx context.Context) error {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/files", nil)
+		resp, err := Do(ctx, http.DefaultClient, cfg, req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// only one of the concurrent requests after the cooldown is sent as the trial
+	var wg sync.WaitGroup
+	var rejected int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := get(context.Background()); errors.Is(err, ErrCircuitOpen) {
+				atomic.AddInt32(&rejected, 1)
+			}
+		}()
+	}
+
+	for atomic.LoadInt32(&rejected) < 9 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected 1 trial request, got %d", n-1)
+	}
+
+	// the successful trial closed the circuit
+	if err := get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_CircuitBreakerRelease(t *testing.T) {
+	breaker := &config.CircuitBreaker{Threshold: 1, Cooldown: time.Millisecond}
+	breaker.Record(true)
+	time.Sleep(2 * time.Millisecond)
+
+	if !breaker.Allow() {
+		t.Fatal("expected trial after cooldown")
+	}
+	if breaker.Allow() {
+		t.Fatal("expected a single trial")
+	}
+
+	// a trial ending without outcome lets the next one through
+	breaker.Release()
+	if !breaker.Allow() {
+		t.Fatal("expected trial after release")
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/imagekit-developer/imagekit-go/config"
+)
+
+// retryable reports whether the outcome of req may be retried: an outcome accepted by the
+// configured RetryPolicy, or DefaultRetryPolicy, of an idempotent request, or of any request when
+// RetryNonIdempotent is set.
+func retryable(cfg *config.Configuration, req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+	default:
+		if !cfg.API.RetryNonIdempotent {
+			return false
+		}
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if cfg.API.RetryPolicy != nil {
+		return cfg.API.RetryPolicy(resp, err)
+	}
+	return DefaultRetryPolicy(resp, err)
+}
+
+// DefaultRetryPolicy is the retry policy used unless config.WithRetryPolicy is set. It retries
+// connection errors and 429, 500, 502, 503 and 504 responses.
+func DefaultRetryPolicy(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns the delay before retry number attempt, starting at 1. The Retry-After header
+// of resp is used when present, otherwise RetryBaseDelay doubled for each previous attempt with
+// jitter of up to half the delay. Either is capped at RetryMaxDelay.
+func retryDelay(cfg *config.Configuration, attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if cfg.API.RetryMaxDelay > 0 && d > cfg.API.RetryMaxDelay {
+				d = cfg.API.RetryMaxDelay
+			}
+			return d
+		}
+	}
+
+	delay := cfg.API.RetryBaseDelay
+	for i := 1; i < attempt && (cfg.API.RetryMaxDelay == 0 || delay < cfg.API.RetryMaxDelay); i++ {
+		delay *= 2
+	}
+
+	if cfg.API.RetryMaxDelay > 0 && delay > cfg.API.RetryMaxDelay {
+		delay = cfg.API.RetryMaxDelay
+	}
+
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+	return delay
+}
+
+// retryAfter parses a Retry-After header value given in seconds or as an http date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,24 @@
+package uploader
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path"
+	"strings"
+)
+
+// FileNameFromTemplate builds a file name from template by replacing {name} with the base name of
+// fileName, {ext} with its extension without the dot and {hash} with the first 8 hex characters of
+// the SHA-1 of content. For example "{name}_{hash}.{ext}" turns "photo.jpg" into "photo_1a2b3c4d.jpg".
+// Upload passes the decoded content of base64 data, and the url itself for urls, whose content is
+// not known to the client.
+func FileNameFromTemplate(template string, fileName string, content []byte) string {
+	ext := path.Ext(fileName)
+	sum := sha1.Sum(content)
+
+	return strings.NewReplacer(
+		"{name}", strings.TrimSuffix(fileName, ext),
+		"{ext}", strings.TrimPrefix(ext, "."),
+		"{hash}", hex.EncodeToString(sum[:])[:8],
+	).Replace(template)
+}
@@ -0,0 +1,110 @@
+package uploader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/imagekit-developer/imagekit-go/api"
+	"github.com/imagekit-developer/imagekit-go/api/media"
+)
+
+// sniffLen is the number of bytes considered by http.DetectContentType.
+const sniffLen = 512
+
+// DetectFileType reports whether file is an image or not before it is uploaded. file can be
+// anything accepted by Upload or a local file path:
+//   - readers are sniffed and must implement io.Seeker as they are rewound afterwards
+//   - base64 data URIs are sniffed after decoding
+//   - local file paths are sniffed and their extension is checked for formats which can not be
+//     sniffed, e.g. SVG
+//   - remote urls are classified by the extension of their path only
+func DetectFileType(file interface{}) (media.FileType, error) {
+	switch f := file.(type) {
+	case io.ReadSeeker:
+		start, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", err
+		}
+
+		head, err := io.ReadAll(io.LimitReader(f, sniffLen))
+		if err != nil {
+			return "", err
+		}
+
+		if _, err = f.Seek(start, io.SeekStart); err != nil {
+			return "", err
+		}
+		return sniffFileType(head), nil
+
+	case io.Reader:
+		return "", errors.New("DetectFileType: reader must implement io.Seeker to be rewound after sniffing")
+
+	case string:
+		return detectStringFileType(f)
+	}
+
+	return "", errors.New("DetectFileType: unsupported file type")
+}
+
+func detectStringFileType(file string) (media.FileType, error) {
+	if api.IsBase64Data(file) {
+		data := file[strings.Index(file, ",")+1:]
+		if len(data) > sniffLen*2 {
+			data = data[:sniffLen*2]
+		}
+
+		head, err := base64.StdEncoding.DecodeString(data[:len(data)/4*4])
+		if err != nil {
+			return "", err
+		}
+		return sniffFileType(head), nil
+	}
+
+	if u, err := url.Parse(file); err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "ftp") {
+		ext := path.Ext(u.Path)
+		if ext == "" {
+			return "", fmt.Errorf("DetectFileType: can not detect type of url without extension %s", file)
+		}
+		return extensionFileType(ext), nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head, err := io.ReadAll(io.LimitReader(f, sniffLen))
+	if err != nil {
+		return "", err
+	}
+
+	if sniffFileType(head) == media.Image {
+		return media.Image, nil
+	}
+	return extensionFileType(filepath.Ext(file)), nil
+}
+
+func sniffFileType(head []byte) media.FileType {
+	if strings.HasPrefix(http.DetectContentType(head), "image/") || bytes.Contains(head, []byte("<svg")) {
+		return media.Image
+	}
+	return media.NonImage
+}
+
+func extensionFileType(ext string) media.FileType {
+	if strings.HasPrefix(mime.TypeByExtension(strings.ToLower(ext)), "image/") {
+		return media.Image
+	}
+	return media.NonImage
+}
@@ -0,0 +1,79 @@
+package uploader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imagekit-developer/imagekit-go/api/media"
+	iktest "github.com/imagekit-developer/imagekit-go/test"
+)
+
+var pdfData = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+
+func TestDetectFileType(t *testing.T) {
+	dir := t.TempDir()
+
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	svgPath := filepath.Join(dir, "logo.svg")
+	jpgNoExt := filepath.Join(dir, "photo")
+
+	os.WriteFile(pdfPath, pdfData, 0644)
+	os.WriteFile(svgPath, []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), 0644)
+	os.WriteFile(jpgNoExt, ImageFileData, 0644)
+
+	var cases = map[string]struct {
+		file     interface{}
+		expected media.FileType
+	}{
+		"image reader":   {bytes.NewReader(ImageFileData), media.Image},
+		"pdf reader":     {bytes.NewReader(pdfData), media.NonImage},
+		"image data uri": {"data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(ImageFileData), media.Image},
+		"pdf data uri":   {"data:application/pdf;base64," + base64.StdEncoding.EncodeToString(pdfData), media.NonImage},
+		"image path":     {iktest.ImageFilePath, media.Image},
+		"image no ext":   {jpgNoExt, media.Image},
+		"pdf path":       {pdfPath, media.NonImage},
+		"svg path":       {svgPath, media.Image},
+		"image url":      {"https://example.com/photos/a.JPG?w=100", media.Image},
+		"pdf url":        {"https://example.com/docs/a.pdf", media.NonImage},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result, err := DetectFileType(tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, result)
+			}
+		})
+	}
+
+	reader := bytes.NewReader(ImageFileData)
+	reader.Seek(10, io.SeekStart)
+
+	if _, err := DetectFileType(reader); err != nil {
+		t.Fatal(err)
+	}
+
+	if pos, _ := reader.Seek(0, io.SeekCurrent); pos != 10 {
+		t.Errorf("reader not rewound, at %d", pos)
+	}
+
+	for name, file := range map[string]interface{}{
+		"non seekable reader": onceReader{strings.NewReader("data")},
+		"url without ext":     "https://example.com/download",
+		"missing path":        filepath.Join(dir, "missing.jpg"),
+		"unsupported":         5,
+	} {
+		if _, err := DetectFileType(file); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package uploader
+
+import "io"
+
+// progressReader reports the bytes read from r and the total size of r to fn after each read.
+type progressReader struct {
+	r     io.Reader
+	total int64
+	sent  int64
+	fn    func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.fn(p.sent, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,58 @@
+package uploader
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// throttledReader limits reading from r to rate bytes per second. It reads in chunks of about a
+// tenth of the rate and stops waiting when ctx is done.
+type throttledReader struct {
+	ctx   context.Context
+	r     io.Reader
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+func newThrottledReader(ctx context.Context, r io.Reader, rate int64) *throttledReader {
+	return &throttledReader{ctx: ctx, r: r, rate: rate}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+
+	chunk := int(t.rate / 10)
+	if chunk < 1 {
+		chunk = 1
+	}
+
+	if len(p) > chunk {
+		p = p[:chunk]
+	}
+
+	n, err := t.r.Read(p)
+	t.read += int64(n)
+
+	if wait := t.wait(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-t.ctx.Done():
+			return n, t.ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return n, err
+}
+
+// wait returns how long to wait until the bytes read so far are within the rate.
+func (t *throttledReader) wait() time.Duration {
+	// in float seconds, as read multiplied by time.Second overflows past about 9.2GB
+	return time.Duration(float64(t.read)/float64(t.rate)*float64(time.Second)) - time.Since(t.start)
+}
@@ -0,0 +1,92 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PostTransformationType is the kind of a PostTransformation.
+type PostTransformationType string
+
+const (
+	// PostTransformationTransformation generates a transformed version of the file, Value holds
+	// the transformation string, e.g. "w-200,h-200".
+	PostTransformationTransformation PostTransformationType = "transformation"
+	// PostTransformationGifToVideo converts a gif to a video, Value optionally holds a
+	// transformation of the video.
+	PostTransformationGifToVideo PostTransformationType = "gif-to-video"
+	// PostTransformationThumbnail generates a thumbnail of a video, Value optionally holds a
+	// transformation of the thumbnail.
+	PostTransformationThumbnail PostTransformationType = "thumbnail"
+	// PostTransformationAbs generates adaptive bitrate streams of a video, Value holds the
+	// resolutions, e.g. "sr-240_360_480", and Protocol is required.
+	PostTransformationAbs PostTransformationType = "abs"
+)
+
+// StreamProtocol is the streaming protocol of an adaptive bitrate post transformation.
+type StreamProtocol string
+
+const (
+	Hls  StreamProtocol = "hls"
+	Dash StreamProtocol = "dash"
+)
+
+// UploadTransformation describes transformations of the uploaded file. Pre is applied before the
+// file is stored, Post generates additional versions after the upload. The results of post
+// transformations are reported to UploadParam.WebhookUrl.
+type UploadTransformation struct {
+	Pre  string               `json:"pre,omitempty"`
+	Post []PostTransformation `json:"post,omitempty"`
+}
+
+// PostTransformation is a transformation run after the upload.
+type PostTransformation struct {
+	Type     PostTransformationType `json:"type"`
+	Value    string                 `json:"value,omitempty"`
+	Protocol StreamProtocol         `json:"protocol,omitempty"`
+}
+
+// Validate checks the fields required by the type of each post transformation.
+func (t UploadTransformation) Validate() error {
+	if t.Pre == "" && len(t.Post) == 0 {
+		return errors.New("transformation: pre or post is required")
+	}
+
+	for i, post := range t.Post {
+		if err := post.Validate(); err != nil {
+			return fmt.Errorf("transformation: post[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the fields required by the type of p.
+func (p PostTransformation) Validate() error {
+	switch p.Type {
+	case PostTransformationTransformation:
+		if p.Value == "" {
+			return errors.New("value is required")
+		}
+	case PostTransformationGifToVideo, PostTransformationThumbnail:
+	case PostTransformationAbs:
+		if p.Value == "" {
+			return errors.New("value is required")
+		}
+
+		switch p.Protocol {
+		case Hls, Dash:
+			return nil
+		case "":
+			return errors.New("protocol is required")
+		default:
+			return fmt.Errorf("unknown protocol %q", p.Protocol)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+
+	if p.Protocol != "" {
+		return fmt.Errorf("protocol is only supported by %s", PostTransformationAbs)
+	}
+	return nil
+}
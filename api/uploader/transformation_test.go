@@ -0,0 +1,63 @@
+package uploader
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPostTransformation_Validate(t *testing.T) {
+	var cases = map[string]struct {
+		post     PostTransformation
+		valid    bool
+		expected string
+	}{
+		"transformation":          {PostTransformation{Type: PostTransformationTransformation, Value: "w-200"}, true, `{"type":"transformation","value":"w-200"}`},
+		"transformation no value": {PostTransformation{Type: PostTransformationTransformation}, false, ""},
+		"gif to video":            {PostTransformation{Type: PostTransformationGifToVideo}, true, `{"type":"gif-to-video"}`},
+		"gif to video value":      {PostTransformation{Type: PostTransformationGifToVideo, Value: "w-400"}, true, `{"type":"gif-to-video","value":"w-400"}`},
+		"thumbnail":               {PostTransformation{Type: PostTransformationThumbnail, Value: "w-150,h-150"}, true, `{"type":"thumbnail","value":"w-150,h-150"}`},
+		"thumbnail protocol":      {PostTransformation{Type: PostTransformationThumbnail, Protocol: Hls}, false, ""},
+		"abs hls":                 {PostTransformation{Type: PostTransformationAbs, Value: "sr-240_360_480", Protocol: Hls}, true, `{"type":"abs","value":"sr-240_360_480","protocol":"hls"}`},
+		"abs dash":                {PostTransformation{Type: PostTransformationAbs, Value: "sr-240_360", Protocol: Dash}, true, `{"type":"abs","value":"sr-240_360","protocol":"dash"}`},
+		"abs no protocol":         {PostTransformation{Type: PostTransformationAbs, Value: "sr-240_360"}, false, ""},
+		"abs unknown protocol":    {PostTransformation{Type: PostTransformationAbs, Value: "sr-240_360", Protocol: "rtmp"}, false, ""},
+		"abs no value":            {PostTransformation{Type: PostTransformationAbs, Protocol: Hls}, false, ""},
+		"unknown type":            {PostTransformation{Type: "resize", Value: "w-200"}, false, ""},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.post.Validate()
+			if !tc.valid {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			body, _ := json.Marshal(tc.post)
+			if string(body) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, body)
+			}
+		})
+	}
+}
+
+func TestUploadTransformation_Validate(t *testing.T) {
+	if err := (UploadTransformation{}).Validate(); err == nil {
+		t.Error("expected error for an empty transformation")
+	}
+
+	if err := (UploadTransformation{Pre: "w-100"}).Validate(); err != nil {
+		t.Error(err)
+	}
+
+	err := UploadTransformation{Post: []PostTransformation{{Type: PostTransformationThumbnail}, {Type: PostTransformationAbs}}}.Validate()
+	if err == nil || err.Error() != "transformation: post[1]: value is required" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -1,24 +1,37 @@
 package uploader
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/imagekit-developer/imagekit-go/api"
 	"github.com/imagekit-developer/imagekit-go/api/extension"
 )
 
 // UploadParam defines upload parameters
+//
+// When OverwriteFile is true and a file already exists at the target path, ImageKit clears its tags
+// and custom metadata unless told otherwise. To keep them, Upload sends overwriteTags=false when Tags
+// is empty and overwriteCustomMetadata=false when CustomMetadata is empty, unless OverwriteTags or
+// OverwriteCustomMetadata are set explicitly.
 type UploadParam struct {
 	FileName          string `json:"fileName"`
 	UseUniqueFileName *bool  `json:"useUniqueFileName,omitempty"`
 	Tags              string `json:"tags,omitempty"`
-	Folder            string `json:"folder,omitempty"`        // default value:  /
-	IsPrivateFile     *bool  `json:"isPrivateFile,omitempty"` // default: false
-	CustomCoordinates string `json:"customCoordinates,omitempty"`
-	ResponseFields    string `json:"responseFields,omitempty"`
-	ExtensionsJson    string `json:"extensions,omitempty"`
+	// TagList is a convenience alternative to Tags. Its entries are appended to Tags.
+	TagList           []string `json:"-"`
+	Folder            string   `json:"folder,omitempty"`        // default value:  /
+	IsPrivateFile     *bool    `json:"isPrivateFile,omitempty"` // default: false
+	CustomCoordinates string   `json:"customCoordinates,omitempty"`
+	ResponseFields    string   `json:"responseFields,omitempty"`
+	ExtensionsJson    string   `json:"extensions,omitempty"`
 
 	Extensions              []extension.IExtension `json:"-"`
 	WebhookUrl              string                 `json:"webhookUrl,omitempty"`
@@ -27,6 +40,20 @@ type UploadParam struct {
 	OverwriteTags           *bool                  `json:"overwriteTags,omitempty"`
 	OverwriteCustomMetadata *bool                  `json:"overwriteCustomMetadata,omitempty"`
 	CustomMetadata          map[string]any         `json:"customMetadata,omitempty"`
+
+	// Transformation is applied to the uploaded file, see UploadTransformation. It is validated
+	// and sent as TransformationJson.
+	Transformation     *UploadTransformation `json:"-"`
+	TransformationJson string                `json:"transformation,omitempty"`
+
+	// FileNameTemplate renames the file on the client before upload, see FileNameFromTemplate.
+	// It requires UseUniqueFileName to be false and does not replace the server side unique
+	// file name feature, which appends a random suffix instead.
+	FileNameTemplate string `json:"-"`
+
+	// Progress is called as the request body is sent with the bytes sent so far and the size of
+	// the body, including the form fields. It starts again from zero when the upload is retried.
+	Progress func(sent, total int64) `json:"-"`
 }
 
 type UploadResult struct {
@@ -50,9 +77,10 @@ type UploadResponse struct {
 // Upload uploads an asset to a imagekit account.
 //
 // The asset can be:
-//   * the actual data (io.Reader)
-//   * the Data URI (Base64 encoded), max ~60 MB (62,910,000 chars)
-//   * the remote FTP, HTTP or HTTPS URL address of an existing file
+//   - the actual data (io.Reader)
+//   - the Data URI (Base64 encoded), max ~60 MB (62,910,000 chars)
+//   - the remote FTP, HTTP or HTTPS URL address of an existing file
+//   - the path of a local file, which is opened and uploaded like an io.Reader
 //
 // https://docs.imagekit.io/api-reference/upload-file-api/server-side-file-upload
 func (u *API) Upload(ctx context.Context, file interface{}, param UploadParam) (*UploadResponse, error) {
@@ -62,6 +90,37 @@ func (u *API) Upload(ctx context.Context, file interface{}, param UploadParam) (
 		return nil, errors.New("Upload: Filename is required")
 	}
 
+	if param.UseUniqueFileName == nil && u.Config.API.UseUniqueFileName != nil {
+		param.UseUniqueFileName = api.Bool(*u.Config.API.UseUniqueFileName)
+	}
+
+	if len(param.TagList) > 0 {
+		tags := param.TagList
+		if param.Tags != "" {
+			tags = append(strings.Split(param.Tags, ","), tags...)
+		}
+		param.Tags = strings.Join(tags, ",")
+	}
+
+	if u.Config.API.NormalizeTags && param.Tags != "" {
+		tags := api.NormalizeTags(strings.Split(param.Tags, ","), u.Config.API.LowercaseTags)
+		param.Tags = strings.Join(tags, ",")
+	}
+
+	if param.CustomCoordinates != "" {
+		if err = api.ValidateCustomCoordinates(param.CustomCoordinates); err != nil {
+			return nil, fmt.Errorf("Upload: %w", err)
+		}
+	}
+
+	preserveMetadata(&param)
+
+	if param.FileNameTemplate != "" {
+		if file, err = applyFileNameTemplate(file, &param); err != nil {
+			return nil, err
+		}
+	}
+
 	if param.Extensions != nil {
 		bt, err := json.Marshal(param.Extensions)
 		if err != nil {
@@ -70,6 +129,18 @@ func (u *API) Upload(ctx context.Context, file interface{}, param UploadParam) (
 		param.ExtensionsJson = string(bt)
 	}
 
+	if param.Transformation != nil {
+		if err = param.Transformation.Validate(); err != nil {
+			return nil, fmt.Errorf("Upload: %w", err)
+		}
+
+		bt, err := json.Marshal(param.Transformation)
+		if err != nil {
+			return nil, err
+		}
+		param.TransformationJson = string(bt)
+	}
+
 	formParams, err := api.StructToParams(param)
 
 	if err != nil {
@@ -78,10 +149,14 @@ func (u *API) Upload(ctx context.Context, file interface{}, param UploadParam) (
 
 	response := &UploadResponse{}
 
-	resp, err := u.postFile(ctx, file, formParams)
+	ctx, cancel := api.ContextWithTimeout(ctx, u.Config.API.EffectiveUploadTimeout())
+	defer cancel()
+
+	resp, err := u.postFile(ctx, file, formParams, param.Progress)
 	defer api.DeferredBodyClose(resp)
 
-	api.SetResponseMeta(resp, response)
+	meta := api.SetResponseMeta(resp, response)
+	u.Logger.ErrorResponse(meta.StatusCode, meta.Body)
 
 	if err != nil {
 		return response, err
@@ -94,3 +169,73 @@ func (u *API) Upload(ctx context.Context, file interface{}, param UploadParam) (
 	}
 	return response, err
 }
+
+// preserveMetadata disables overwriting of tags and custom metadata on re-upload when new values
+// are not provided and the caller did not decide explicitly.
+func preserveMetadata(param *UploadParam) {
+	if param.OverwriteFile == nil || !*param.OverwriteFile {
+		return
+	}
+
+	if param.OverwriteTags == nil && param.Tags == "" {
+		param.OverwriteTags = api.Bool(false)
+	}
+
+	if param.OverwriteCustomMetadata == nil && len(param.CustomMetadata) == 0 {
+		param.OverwriteCustomMetadata = api.Bool(false)
+	}
+}
+
+// applyFileNameTemplate renames param.FileName using param.FileNameTemplate. Readers are read into
+// memory to compute the content hash and a new reader over the content is returned. base64 data is
+// decoded, so the hash of the same content does not depend on how it is passed. Only the content
+// of urls is not known to the client, their hash is the one of the url.
+func applyFileNameTemplate(file interface{}, param *UploadParam) (interface{}, error) {
+	if param.UseUniqueFileName == nil || *param.UseUniqueFileName {
+		return nil, errors.New("Upload: FileNameTemplate requires UseUniqueFileName to be false")
+	}
+
+	var content []byte
+
+	switch f := file.(type) {
+	case string:
+		if api.IsLocalFilePath(f) {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			content = data
+			break
+		}
+
+		if data, ok := base64Content(f); ok {
+			content = data
+			break
+		}
+		content = []byte(f)
+	case io.Reader:
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		content = data
+		file = bytes.NewReader(data)
+	}
+
+	param.FileName = FileNameFromTemplate(param.FileNameTemplate, param.FileName, content)
+	return file, nil
+}
+
+// base64Content decodes s when it is a base64 data URI or plain base64 data.
+func base64Content(s string) ([]byte, bool) {
+	if api.IsValidURL(s) {
+		return nil, false
+	}
+
+	if api.IsBase64Data(s) {
+		s = s[strings.Index(s, ",")+1:]
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(s, "\n", ""))
+	return data, err == nil
+}
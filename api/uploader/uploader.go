@@ -8,7 +8,8 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
-	"time"
+	"os"
+	"strings"
 
 	"github.com/imagekit-developer/imagekit-go/api"
 	"github.com/imagekit-developer/imagekit-go/config"
@@ -36,84 +37,206 @@ func New() (*API, error) {
 func NewFromConfiguration(c *config.Configuration) (*API, error) {
 	return &API{
 		Config: *c,
-		Client: &http.Client{},
+		Client: api.NewClient(c),
 		Logger: logger.New(),
 	}, nil
 }
 
-// postFile uploads file with url.Values parameters
-func (u *API) postFile(ctx context.Context, file interface{}, formParams url.Values) (*http.Response, error) {
+// postFile uploads file with url.Values parameters. progress, when not nil, is called as the
+// request body is sent.
+func (u *API) postFile(ctx context.Context, file interface{}, formParams url.Values, progress func(sent, total int64)) (*http.Response, error) {
 	uploadEndpoint := api.BuildPath("files", "upload")
 
 	switch fileValue := file.(type) {
 	case string:
-		// Can be URL, Base64 encoded string, etc.
+		// Can be URL, Base64 encoded string, local file path, etc.
+		if strings.HasPrefix(fileValue, "data:") && !api.IsBase64Data(fileValue) {
+			return nil, errors.New("invalid base64 data uri")
+		}
+
+		if api.IsLocalFilePath(fileValue) {
+			f, err := os.Open(fileValue)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+
+			return u.postIOReader(ctx, uploadEndpoint, f, formParams, map[string]string{}, progress)
+		}
 		formParams.Add("file", fileValue)
-		return u.postForm(ctx, uploadEndpoint, formParams)
+		return u.postForm(ctx, uploadEndpoint, formParams, progress)
 	case io.Reader:
-		return u.postIOReader(ctx, uploadEndpoint, fileValue, formParams, map[string]string{})
+		return u.postIOReader(ctx, uploadEndpoint, fileValue, formParams, map[string]string{}, progress)
 
 	default:
 		return nil, errors.New("unsupported file type")
 	}
 }
 
-// postIOReader uploads file using io.Reader
-func (u *API) postIOReader(ctx context.Context, urlPath string, reader io.Reader, formParams url.Values, headers map[string]string) (*http.Response, error) {
+// postIOReader uploads file using io.Reader. An io.Seeker, such as a local file, is streamed and
+// rewound to its current offset to send it again on retries and failover. The content of other
+// readers is buffered in memory, so they can be sent again as well, at the cost of holding the
+// whole file.
+func (u *API) postIOReader(ctx context.Context, urlPath string, reader io.Reader, formParams url.Values, headers map[string]string, progress func(sent, total int64)) (*http.Response, error) {
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		if body, size, contentType, ok := seekableBody(seeker, formParams); ok {
+			headers["Content-Type"] = contentType
+			return u.postBody(ctx, urlPath, body, size, headers, progress)
+		}
+	}
+
+	bodyBuf, contentType, err := multipartBody(reader, formParams)
+	if err != nil {
+		return nil, err
+	}
+	headers["Content-Type"] = contentType
+
+	return u.postBuffer(ctx, urlPath, bodyBuf, headers, progress)
+}
+
+// multipartBody writes formParams and the content of reader as file field into a multipart body.
+func multipartBody(reader io.Reader, formParams url.Values) (*bytes.Buffer, string, error) {
 	bodyBuf := new(bytes.Buffer)
 	formWriter := multipart.NewWriter(bodyBuf)
 
-	headers["Content-Type"] = formWriter.FormDataContentType()
-
 	for key, val := range formParams {
 		_ = formWriter.WriteField(key, val[0])
 	}
 
 	partWriter, err := formWriter.CreateFormFile("file", formParams.Get("fileName"))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if _, err = io.Copy(partWriter, reader); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if err = formWriter.Close(); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	if u.Config.API.UploadTimeout != 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, time.Duration(u.Config.API.UploadTimeout)*time.Second)
-		defer cancel()
+	return bodyBuf, formWriter.FormDataContentType(), nil
+}
+
+// seekableBody returns a multipart body with formParams and the content of seeker from its current
+// offset as file field. Each call of body seeks back to that offset. ok is false when seeker can
+// not seek, e.g. a pipe opened as *os.File.
+func seekableBody(seeker io.ReadSeeker, formParams url.Values) (body func() (io.Reader, error), size int64, contentType string, ok bool) {
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, "", false
+	}
+
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, "", false
 	}
 
-	return u.postBody(ctx, urlPath, bodyBuf, headers)
+	if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, 0, "", false
+	}
+
+	// the writer writes the part header and closing boundary to buf, the content is streamed
+	// in between
+	buf := new(bytes.Buffer)
+	formWriter := multipart.NewWriter(buf)
+
+	for key, val := range formParams {
+		_ = formWriter.WriteField(key, val[0])
+	}
+
+	if _, err = formWriter.CreateFormFile("file", formParams.Get("fileName")); err != nil {
+		return nil, 0, "", false
+	}
+	head := append([]byte(nil), buf.Bytes()...)
+
+	buf.Reset()
+	if err = formWriter.Close(); err != nil {
+		return nil, 0, "", false
+	}
+	tail := buf.Bytes()
+
+	body = func() (io.Reader, error) {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.MultiReader(bytes.NewReader(head), io.LimitReader(seeker, end-start), bytes.NewReader(tail)), nil
+	}
+
+	return body, int64(len(head)+len(tail)) + end - start, formWriter.FormDataContentType(), true
+}
+
+// postBuffer posts the content of bodyBuf, see postBody.
+func (u *API) postBuffer(ctx context.Context, urlPath string, bodyBuf *bytes.Buffer, headers map[string]string, progress func(sent, total int64)) (*http.Response, error) {
+	data := bodyBuf.Bytes()
+
+	body := func() (io.Reader, error) {
+		return bytes.NewReader(data), nil
+	}
+
+	return u.postBody(ctx, urlPath, body, int64(len(data)), headers, progress)
 }
 
-func (u *API) postBody(ctx context.Context, urlPath string, bodyBuf *bytes.Buffer, headers map[string]string) (*http.Response, error) {
+// postBody posts the size bytes returned by body. body is called again to replay the request, so
+// failed uploads are retried by api.Do: up to UploadRetries times when set, regardless of
+// RetryNonIdempotent, otherwise as configured by WithRetries. The progress of a retried upload
+// starts again from zero.
+func (u *API) postBody(ctx context.Context, urlPath string, body func() (io.Reader, error), size int64, headers map[string]string, progress func(sent, total int64)) (*http.Response, error) {
+	newBody := func() (io.Reader, error) {
+		r, err := body()
+		if err != nil {
+			return nil, err
+		}
+
+		if u.Config.API.UploadRateLimit > 0 {
+			r = newThrottledReader(ctx, r, u.Config.API.UploadRateLimit)
+		}
+
+		if progress != nil {
+			r = &progressReader{r: r, total: size, fn: progress}
+		}
+		return r, nil
+	}
+
+	first, err := newBody()
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequest(http.MethodPost,
 		u.Config.API.UploadPrefix+urlPath,
-		bodyBuf,
+		first,
 	)
 
 	if err != nil {
 		return nil, err
 	}
-
-	req.SetBasicAuth(u.Config.Cloud.PrivateKey, "")
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		r, err := newBody()
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(r), nil
+	}
 
 	for key, val := range headers {
 		req.Header.Add(key, val)
 	}
 
-	req = req.WithContext(ctx)
+	cfg := u.Config
+	if cfg.API.UploadRetries > 0 {
+		cfg.API.MaxRetries = cfg.API.UploadRetries
+		cfg.API.RetryNonIdempotent = true
+	}
 
-	return u.Client.Do(req)
+	return api.Do(ctx, u.Client, &cfg, req)
 }
 
-func (u *API) postForm(ctx context.Context, urlPath string, formParams url.Values) (*http.Response, error) {
+// postForm posts formParams as multipart/form-data. The upload api does not accept a json body, so
+// base64 data URIs and urls are sent verbatim in the file field.
+func (u *API) postForm(ctx context.Context, urlPath string, formParams url.Values, progress func(sent, total int64)) (*http.Response, error) {
 
 	bodyBuf := new(bytes.Buffer)
 	writer := multipart.NewWriter(bodyBuf)
@@ -127,8 +250,6 @@ func (u *API) postForm(ctx context.Context, urlPath string, formParams url.Value
 	}
 
 	h := map[string]string{"Content-Type": writer.FormDataContentType()}
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(u.Config.API.Timeout)*time.Second)
-	defer cancel()
 
-	return u.postBody(ctx, urlPath, bodyBuf, h)
+	return u.postBuffer(ctx, urlPath, bodyBuf, h, progress)
 }
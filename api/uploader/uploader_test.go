@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"mime"
@@ -12,7 +13,10 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/imagekit-developer/imagekit-go/api"
@@ -258,10 +262,727 @@ func Test_postFile(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err = uploader.postFile(ctx, 5, url.Values{})
+	_, err = uploader.postFile(ctx, 5, url.Values{}, nil)
 
 	if err == nil {
 		t.Error("expected error")
 	}
 
 }
+
+// formValues parses multipart form values of the recorded request.
+func formValues(t *testing.T, httpTest *iktest.Http) map[string]string {
+	_, params, err := mime.ParseMediaType(httpTest.Req.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(httpTest.Body), params["boundary"]).ReadForm(1024 * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values = map[string]string{}
+	for k, v := range form.Value {
+		values[k] = v[0]
+	}
+	return values
+}
+
+func TestUploader_CustomCoordinates(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = uploader.Upload(ctx, bytes.NewReader(ImageFileData), UploadParam{
+		FileName:          "a.jpg",
+		CustomCoordinates: "10,10,100,200",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if values := formValues(t, httpTest); values["customCoordinates"] != "10,10,100,200" {
+		t.Errorf("unexpected customCoordinates: %q", values["customCoordinates"])
+	}
+
+	for _, invalid := range []string{"10,10,100", "10,10,100,200,5", "-1,0,10,10", "0,0,0,10", "a,b,c,d", "1.5,0,10,10"} {
+		if _, err = uploader.Upload(ctx, "https://example.com/a.jpg", UploadParam{
+			FileName:          "a.jpg",
+			CustomCoordinates: invalid,
+		}); err == nil {
+			t.Errorf("%s: expected error", invalid)
+		}
+	}
+}
+
+func TestUploader_Transformation(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = uploader.Upload(ctx, "https://example.com/a.mp4", UploadParam{
+		FileName: "a.mp4",
+		Transformation: &UploadTransformation{
+			Pre: "w-1280",
+			Post: []PostTransformation{
+				{Type: PostTransformationThumbnail, Value: "w-150"},
+				{Type: PostTransformationAbs, Value: "sr-240_360_480", Protocol: Hls},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"pre":"w-1280","post":[{"type":"thumbnail","value":"w-150"},{"type":"abs","value":"sr-240_360_480","protocol":"hls"}]}`
+	if got := formValues(t, httpTest)["transformation"]; got != expected {
+		t.Errorf("expected transformation %s, got %s", expected, got)
+	}
+
+	if _, err = uploader.Upload(ctx, "https://example.com/a.mp4", UploadParam{
+		FileName:       "a.mp4",
+		Transformation: &UploadTransformation{Post: []PostTransformation{{Type: PostTransformationAbs, Value: "sr-240"}}},
+	}); err == nil {
+		t.Error("expected error for abs without protocol")
+	}
+}
+
+func TestUploader_DefaultUseUniqueFileName(t *testing.T) {
+	var cases = map[string]struct {
+		def      *bool
+		param    *bool
+		expected string
+	}{
+		"no default":           {nil, nil, ""},
+		"inherit false":        {api.Bool(false), nil, "false"},
+		"inherit true":         {api.Bool(true), nil, "true"},
+		"override to true":     {api.Bool(false), api.Bool(true), "true"},
+		"override to false":    {api.Bool(true), api.Bool(false), "false"},
+		"explicit without def": {nil, api.Bool(false), "false"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpTest := iktest.NewHttp(t)
+			ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+			defer ts.Close()
+
+			uploader, err := newUploader(ts.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			uploader.Config.API.UseUniqueFileName = tc.def
+
+			if _, err = uploader.Upload(ctx, "https://example.com/a.jpg", UploadParam{
+				FileName:          "a.jpg",
+				UseUniqueFileName: tc.param,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := formValues(t, httpTest)["useUniqueFileName"]; got != tc.expected {
+				t.Errorf("expected useUniqueFileName %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestUploader_PreserveMetadata(t *testing.T) {
+	var cases = map[string]struct {
+		param    UploadParam
+		expected map[string]string
+	}{
+		"overwrite without tags and metadata": {
+			param: UploadParam{FileName: "a.jpg", OverwriteFile: api.Bool(true)},
+			expected: map[string]string{
+				"overwriteTags":           "false",
+				"overwriteCustomMetadata": "false",
+			},
+		},
+		"overwrite with new tags and metadata": {
+			param: UploadParam{
+				FileName:       "a.jpg",
+				OverwriteFile:  api.Bool(true),
+				Tags:           "one,two",
+				CustomMetadata: map[string]any{"price": 10},
+			},
+			expected: map[string]string{
+				"overwriteTags":           "",
+				"overwriteCustomMetadata": "",
+			},
+		},
+		"explicit overwrite flags": {
+			param: UploadParam{
+				FileName:                "a.jpg",
+				OverwriteFile:           api.Bool(true),
+				OverwriteTags:           api.Bool(true),
+				OverwriteCustomMetadata: api.Bool(true),
+			},
+			expected: map[string]string{
+				"overwriteTags":           "true",
+				"overwriteCustomMetadata": "true",
+			},
+		},
+		"no overwrite": {
+			param: UploadParam{FileName: "a.jpg"},
+			expected: map[string]string{
+				"overwriteTags":           "",
+				"overwriteCustomMetadata": "",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpTest := iktest.NewHttp(t)
+			ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+			defer ts.Close()
+
+			uploader, err := newUploader(ts.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = uploader.Upload(ctx, iktest.Base64Image, tc.param); err != nil {
+				t.Fatal(err)
+			}
+
+			values := formValues(t, httpTest)
+			for k, v := range tc.expected {
+				if values[k] != v {
+					t.Errorf("%s: expected %q, got %q", k, v, values[k])
+				}
+			}
+		})
+	}
+}
+
+func TestUploader_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(200)
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uploader.Config.API.RequestTimeout = 20 * time.Millisecond
+	uploader.Config.API.UploadRequestTimeout = time.Minute
+
+	if _, err = uploader.Upload(ctx, iktest.Base64Image, UploadParam{FileName: "a.gif"}); err != nil {
+		t.Errorf("upload should use upload timeout: %v", err)
+	}
+
+	uploader.Config.API.UploadRequestTimeout = 20 * time.Millisecond
+
+	if _, err = uploader.Upload(ctx, iktest.Base64Image, UploadParam{FileName: "a.gif"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if _, err = uploader.Upload(dctx, iktest.Base64Image, UploadParam{FileName: "a.gif"}); err != nil {
+		t.Errorf("context deadline should win over upload timeout: %v", err)
+	}
+}
+
+func TestFileNameFromTemplate(t *testing.T) {
+	var cases = map[string]struct {
+		template string
+		fileName string
+		result   string
+	}{
+		"name hash ext": {
+			template: "{name}_{hash}.{ext}",
+			fileName: "photo.jpg",
+			result:   "photo_a94a8fe5.jpg",
+		},
+		"static prefix": {
+			template: "uploads-{name}.{ext}",
+			fileName: "archive.tar.gz",
+			result:   "uploads-archive.tar.gz",
+		},
+		"no extension": {
+			template: "{name}-{hash}",
+			fileName: "README",
+			result:   "README-a94a8fe5",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result := FileNameFromTemplate(tc.template, tc.fileName, []byte("test"))
+			if result != tc.result {
+				t.Errorf("expected: %s, got: %s", tc.result, result)
+			}
+		})
+	}
+}
+
+func TestUploader_FileNameTemplate(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	param := UploadParam{
+		FileName:          "photo.jpg",
+		FileNameTemplate:  "{name}_{hash}.{ext}",
+		UseUniqueFileName: api.Bool(false),
+	}
+
+	if _, err = uploader.Upload(ctx, strings.NewReader("test"), param); err != nil {
+		t.Fatal(err)
+	}
+
+	values := formValues(t, httpTest)
+	if values["fileName"] != "photo_a94a8fe5.jpg" {
+		t.Errorf("unexpected file name: %s", values["fileName"])
+	}
+
+	// the hash is of the content, however it is passed
+	for _, file := range []string{"data:text/plain;base64,dGVzdA==", "dGVzdA=="} {
+		if _, err = uploader.Upload(ctx, file, param); err != nil {
+			t.Fatal(err)
+		}
+
+		if name := formValues(t, httpTest)["fileName"]; name != "photo_a94a8fe5.jpg" {
+			t.Errorf("%s: unexpected file name: %s", file, name)
+		}
+	}
+
+	param.UseUniqueFileName = nil
+	if _, err = uploader.Upload(ctx, strings.NewReader("test"), param); err == nil {
+		t.Error("expected error when unique file name is not disabled")
+	}
+}
+
+func TestUploader_TagNormalization(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	param := UploadParam{FileName: "a.gif", Tags: " Summer, summer,,Beach "}
+
+	if _, err = uploader.Upload(ctx, iktest.Base64Image, param); err != nil {
+		t.Fatal(err)
+	}
+
+	if tags := formValues(t, httpTest)["tags"]; tags != param.Tags {
+		t.Errorf("tags should not be normalized by default: %q", tags)
+	}
+
+	uploader.Config.API.NormalizeTags = true
+	uploader.Config.API.LowercaseTags = true
+
+	if _, err = uploader.Upload(ctx, iktest.Base64Image, param); err != nil {
+		t.Fatal(err)
+	}
+
+	if tags := formValues(t, httpTest)["tags"]; tags != "summer,beach" {
+		t.Errorf("unexpected tags: %q", tags)
+	}
+}
+
+func TestUploader_Base64DataUri(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dataUri = "data:image/gif;name=pixel;base64,R0lGODlhAQABAIAAAAAAAP///yH5BAEAAAAALAAAAAABAAEAAAIBRAA7"
+
+	if _, err = uploader.Upload(ctx, dataUri, UploadParam{FileName: "pixel.gif"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(httpTest.Req.Header.Get("Content-Type"), "multipart/form-data; boundary=") {
+		t.Errorf("unexpected content type: %s", httpTest.Req.Header.Get("Content-Type"))
+	}
+
+	values := formValues(t, httpTest)
+	if values["file"] != dataUri {
+		t.Errorf("unexpected file field: %s", values["file"])
+	}
+
+	if _, err = uploader.Upload(ctx, "data:image/gif;base64,not base64!", UploadParam{FileName: "pixel.gif"}); err == nil {
+		t.Error("expected error for invalid data uri")
+	}
+}
+
+func TestUploader_LocalFilePath(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, "{}"))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = uploader.Upload(ctx, iktest.ImageFilePath, UploadParam{
+		FileName: "a.jpg",
+		Tags:     "city",
+		TagList:  []string{"night", "skyline"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, params, err := mime.ParseMediaType(httpTest.Req.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(httpTest.Body), params["boundary"]).ReadForm(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(form.File["file"]) != 1 {
+		t.Fatalf("expected file part, got values %v", form.Value["file"])
+	}
+
+	info, err := os.Stat(iktest.ImageFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if form.File["file"][0].Size != info.Size() {
+		t.Errorf("unexpected file size: %d", form.File["file"][0].Size)
+	}
+
+	if tags := form.Value["tags"][0]; tags != "city,night,skyline" {
+		t.Errorf("unexpected tags: %q", tags)
+	}
+}
+
+// onceReader hides the Seek method of the underlying reader.
+type onceReader struct {
+	io.Reader
+}
+
+// rewindSeeker records the bytes read and the offsets sought back to from the start.
+type rewindSeeker struct {
+	*bytes.Reader
+	read    int
+	rewinds []int64
+}
+
+func (r *rewindSeeker) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func (r *rewindSeeker) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekStart {
+		r.rewinds = append(r.rewinds, offset)
+	}
+	return r.Reader.Seek(offset, whence)
+}
+
+func TestUploader_Retry(t *testing.T) {
+	var calls int
+	var received [][]byte
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if file, _, err := r.FormFile("file"); err == nil {
+			data, _ := io.ReadAll(file)
+			received = append(received, data)
+		}
+
+		if calls == 1 {
+			w.WriteHeader(503)
+			w.Write([]byte("{}"))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploader.Config.API.UploadRetries = 2
+	uploader.Config.API.RetryBaseDelay = time.Millisecond
+
+	// the upload starts at the current offset of the seeker, after a prefix already read
+	seeker := &rewindSeeker{Reader: bytes.NewReader(append([]byte("abc"), ImageFileData...))}
+	seeker.Reader.Seek(3, io.SeekStart)
+
+	if _, err = uploader.Upload(ctx, seeker, UploadParam{FileName: "a.jpg"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls)
+	}
+
+	for i, data := range received {
+		if !bytes.Equal(data, ImageFileData) {
+			t.Errorf("attempt %d: file not read from the start", i+1)
+		}
+	}
+
+	// streamed from the seeker, which is rewound for the retry instead of being buffered
+	if seeker.read != 2*len(ImageFileData) {
+		t.Errorf("expected the file to be read once per attempt, read %d bytes", seeker.read)
+	}
+
+	for _, offset := range seeker.rewinds {
+		if offset != 3 {
+			t.Errorf("expected rewinds to offset 3, got %v", seeker.rewinds)
+			break
+		}
+	}
+
+	if len(seeker.rewinds) < 3 {
+		t.Errorf("expected a rewind per attempt, got %v", seeker.rewinds)
+	}
+
+	calls = 0
+	received = nil
+
+	// the multipart body is buffered, so readers which can not seek are retried as well
+	if _, err = uploader.Upload(ctx, onceReader{bytes.NewReader(ImageFileData)}, UploadParam{FileName: "a.jpg"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 || len(received) != 2 || !bytes.Equal(received[1], ImageFileData) {
+		t.Errorf("expected non seekable reader to be retried, got %d attempts", calls)
+	}
+}
+
+func TestUploader_RetriesDoNotMultiply(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(503)
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploader.Config.API.RetryBaseDelay = time.Millisecond
+	uploader.Config.API.MaxRetries = 3
+	uploader.Config.API.RetryNonIdempotent = true
+
+	if _, err = uploader.Upload(ctx, bytes.NewReader(ImageFileData), UploadParam{FileName: "a.jpg"}); !errors.Is(err, api.ErrServer) {
+		t.Errorf("expected server error, got %v", err)
+	}
+
+	if calls != 4 {
+		t.Errorf("expected MaxRetries+1 attempts, got %d", calls)
+	}
+
+	calls = 0
+	uploader.Config.API.UploadRetries = 1
+
+	if _, err = uploader.Upload(ctx, bytes.NewReader(ImageFileData), UploadParam{FileName: "a.jpg"}); !errors.Is(err, api.ErrServer) {
+		t.Errorf("expected server error, got %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected UploadRetries+1 attempts, got %d", calls)
+	}
+}
+
+func TestUploader_RetryInterruptedWithProgress(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls == 1 {
+			// drop the connection in the middle of the upload
+			io.CopyN(io.Discard, r.Body, 1024)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+
+		if file, _, err := r.FormFile("file"); err != nil {
+			t.Error(err)
+		} else if data, _ := io.ReadAll(file); !bytes.Equal(data, ImageFileData) {
+			t.Error("retried upload not read from the start")
+		}
+		w.WriteHeader(200)
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploader.Config.API.UploadRetries = 1
+	uploader.Config.API.RetryBaseDelay = time.Millisecond
+
+	var sent []int64
+	var total int64
+
+	_, err = uploader.Upload(ctx, bytes.NewReader(ImageFileData), UploadParam{
+		FileName: "a.jpg",
+		Progress: func(n, size int64) {
+			sent = append(sent, n)
+			total = size
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls)
+	}
+
+	if total <= int64(len(ImageFileData)) || sent[len(sent)-1] != total {
+		t.Errorf("expected progress to end at total %d, got %v", total, sent)
+	}
+
+	var restarted bool
+	for i := 1; i < len(sent); i++ {
+		if sent[i] < sent[i-1] {
+			restarted = true
+		}
+	}
+
+	if !restarted {
+		t.Errorf("expected progress to restart with the retry, got %v", sent)
+	}
+}
+
+func TestUploader_RateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(200)
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploader.Config.API.UploadRateLimit = 40000
+
+	payload := bytes.Repeat([]byte("a"), 20000)
+
+	start := time.Now()
+	if _, err = uploader.Upload(ctx, bytes.NewReader(payload), UploadParam{FileName: "a.txt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 20kB plus the multipart envelope at 40kB/s
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("expected an upload of about 500ms, took %v", elapsed)
+	}
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	if _, err = uploader.Upload(cancelCtx, bytes.NewReader(payload), UploadParam{FileName: "a.txt"}); err == nil {
+		t.Error("expected error for canceled context")
+	}
+
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("throttled upload did not stop on cancellation, took %v", elapsed)
+	}
+}
+
+func TestUploader_RateLimitLargeUpload(t *testing.T) {
+	// 20GB read at 1GB/s, 19s after the start
+	r := newThrottledReader(ctx, nil, 1<<30)
+	r.start = time.Now().Add(-19 * time.Second)
+	r.read = 20 << 30
+
+	if wait := r.wait(); wait < 900*time.Millisecond || wait > time.Second {
+		t.Errorf("expected a wait of about 1s, got %v", wait)
+	}
+}
+
+func TestUploader_RateLimitRetry(t *testing.T) {
+	var calls int
+	var received [][]byte
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if file, _, err := r.FormFile("file"); err == nil {
+			data, _ := io.ReadAll(file)
+			received = append(received, data)
+		}
+
+		if calls == 1 {
+			w.WriteHeader(503)
+			w.Write([]byte("{}"))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	uploader, err := newUploader(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploader.Config.API.UploadRateLimit = 32 << 20
+	uploader.Config.API.UploadRetries = 1
+	uploader.Config.API.RetryBaseDelay = time.Millisecond
+
+	if _, err = uploader.Upload(ctx, onceReader{bytes.NewReader(ImageFileData)}, UploadParam{FileName: "a.jpg"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the throttled body is replayed from the start
+	if calls != 2 || len(received) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls)
+	}
+
+	for i, data := range received {
+		if !bytes.Equal(data, ImageFileData) {
+			t.Errorf("attempt %d: file not sent in full", i+1)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import "runtime/debug"
+
+const modulePath = "github.com/imagekit-developer/imagekit-go"
+
+// DefaultUserAgent is the User-Agent header of requests unless config.WithUserAgent is set. It is
+// imagekit-go followed by the version of the module, when the build records it.
+var DefaultUserAgent = userAgent()
+
+func userAgent() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "imagekit-go"
+	}
+
+	var version = ""
+	if info.Main.Path == modulePath {
+		version = info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			version = dep.Version
+		}
+	}
+
+	if version == "" || version == "(devel)" {
+		return "imagekit-go"
+	}
+	return "imagekit-go/" + version
+}
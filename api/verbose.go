@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/imagekit-developer/imagekit-go/config"
+)
+
+// verboseBodyLimit is the number of bytes of a request or response body included in verbose
+// errors, so uploaded files do not flood the error message.
+const verboseBodyLimit = 16 * 1024
+
+// verboseKey is the context key of the request description recorded for verbose errors.
+type verboseKey struct{}
+
+// jsonSecrets and formSecrets match the values of credential fields in json and multipart bodies.
+var (
+	jsonSecrets = regexp.MustCompile(`(?i)("(?:privateKey|password|signature|token)"\s*:\s*)"[^"]*"`)
+	formSecrets = regexp.MustCompile(`(?i)(name="(?:privateKey|password|signature|token)"\r?\n\r?\n)[^\r\n]*`)
+)
+
+// withVerboseRequest returns ctx carrying the method, url and redacted body of req when verbose
+// errors are enabled.
+func withVerboseRequest(ctx context.Context, cfg *config.Configuration, req *http.Request) context.Context {
+	if !cfg.API.VerboseErrors {
+		return ctx
+	}
+
+	var body string
+
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+	case req.GetBody == nil:
+		body = "(body can not be read again)"
+	default:
+		if rc, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(rc, verboseBodyLimit+1))
+			rc.Close()
+			body = truncateBody(data)
+		}
+	}
+
+	body = jsonSecrets.ReplaceAllString(body, `$1"****"`)
+	body = formSecrets.ReplaceAllString(body, `${1}****`)
+	if key := cfg.Cloud.PrivateKey; key != "" {
+		body = strings.ReplaceAll(body, key, "****")
+	}
+
+	return context.WithValue(ctx, verboseKey{}, fmt.Sprintf("request: %s %s\n%s", req.Method, req.URL, body))
+}
+
+// verboseDetails describes the request and response of resp when its request was sent with
+// verbose errors enabled.
+func verboseDetails(resp *http.Response, body []byte) string {
+	if resp.Request == nil {
+		return ""
+	}
+
+	request, ok := resp.Request.Context().Value(verboseKey{}).(string)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s\nresponse: %s\n%s", request, resp.Status, truncateBody(body))
+}
+
+func truncateBody(data []byte) string {
+	if len(data) > verboseBodyLimit {
+		return string(data[:verboseBodyLimit]) + "... (truncated)"
+	}
+	return string(data)
+}
@@ -1,9 +1,72 @@
 package config
 
+import (
+	"net/http"
+	"time"
+)
+
+// HTTPClient sends the requests of the api clients, see WithHTTPClient. It has the method of
+// api.HttpClient, e.g. *http.Client.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 // API defines the configuration for making requests to the ImageKit.io API.
 type API struct {
-	Prefix        string `default:"https://api.imagekit.io/v1/"`
-	UploadPrefix  string `default:"https://upload.imagekit.io/api/v1/"`
-	Timeout       int64  `default:"60"` // seconds
-	UploadTimeout int64  `upload_timeout"`
+	Prefix             string                    `default:"https://api.imagekit.io/v1/"`
+	UploadPrefix       string                    `default:"https://upload.imagekit.io/api/v1/"`
+	UploadRetries      int                       // retries of failed uploads, replaces MaxRetries for uploads
+	UploadRateLimit    int64                     // upload bandwidth in bytes per second, zero for unlimited
+	Failover           []string                  // alternative Prefix values tried in order on connection errors
+	UploadFailover     []string                  // alternative UploadPrefix values tried in order on connection errors
+	Headers            http.Header               // extra headers sent with every request
+	Authorization      string                    // replaces the basic auth header when set
+	UserAgent          string                    // User-Agent header of every request, see WithUserAgent
+	RequestMutator     func(*http.Request) error // called before each request is sent, an error aborts it
+	UseUniqueFileName  *bool                     // default useUniqueFileName of uploads not setting it
+	NormalizeTags      bool                      // trim tags and drop empty ones and duplicates before sending
+	LowercaseTags      bool                      // lowercase tags when NormalizeTags is set
+	MaxRetries         int                       // retries of requests failing with a connection error, 429 or 5xx status
+	RetryBaseDelay     time.Duration             `default:"500ms"` // delay before the first retry, doubled on each further retry
+	RetryMaxDelay      time.Duration             `default:"30s"`   // upper bound of the retry delay, including Retry-After
+	RetryNonIdempotent bool                      // also retry POST and PATCH requests
+	VerboseErrors      bool                      // include request and response bodies in api errors
+	CircuitBreaker     *CircuitBreaker           // fails requests fast after repeated failures, see WithCircuitBreaker
+	HTTPClient         HTTPClient                // client of the api structs, a new http.Client when nil
+	// RequestTimeout and UploadRequestTimeout are the timeouts of management and upload calls,
+	// see WithTimeout and WithUploadTimeout.
+	RequestTimeout       time.Duration `default:"60s"`
+	UploadRequestTimeout time.Duration `default:"10m"`
+	// Deprecated: Timeout is the management call timeout in seconds. When set it takes precedence
+	// over RequestTimeout, use RequestTimeout instead.
+	Timeout int64
+	// Deprecated: UploadTimeout is the upload call timeout in seconds. When set it takes
+	// precedence over UploadRequestTimeout, use UploadRequestTimeout instead.
+	UploadTimeout int64
+	// RetryPolicy decides which responses and errors are retried, see WithRetryPolicy.
+	RetryPolicy func(*http.Response, error) bool
+	// FileTypeTransformations are the default transformations of urls built by FileURL, by file
+	// type such as "image" or "non-image", see WithFileTypeTransformations.
+	FileTypeTransformations map[string][]map[string]any
+	// QueryTransformationFallback moves url transformations to the query when the path contains
+	// characters which conflict with path transformations, see WithQueryTransformationFallback.
+	QueryTransformationFallback bool
+}
+
+// EffectiveTimeout returns the timeout of management calls: the deprecated Timeout when set,
+// RequestTimeout otherwise.
+func (a API) EffectiveTimeout() time.Duration {
+	if a.Timeout != 0 {
+		return time.Duration(a.Timeout) * time.Second
+	}
+	return a.RequestTimeout
+}
+
+// EffectiveUploadTimeout returns the timeout of upload calls: the deprecated UploadTimeout when
+// set, UploadRequestTimeout otherwise.
+func (a API) EffectiveUploadTimeout() time.Duration {
+	if a.UploadTimeout != 0 {
+		return time.Duration(a.UploadTimeout) * time.Second
+	}
+	return a.UploadRequestTimeout
 }
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops sending requests after Threshold consecutive failed requests until Cooldown
+// has passed. A single request is then let through as a trial while all others are still rejected:
+// its success closes the circuit, its failure opens it for another Cooldown. Failed requests are
+// connection errors and 5xx responses, counted after retries. The breaker is shared by the copies
+// of a Configuration, see WithCircuitBreaker.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool // a trial request is in flight
+}
+
+// Allow reports whether a request may be sent. Once the circuit is open, only the first call after
+// the cooldown returns true until the outcome of that trial is reported with Record or Release.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.Threshold {
+		return true
+	}
+
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+// Record updates the breaker with the outcome of a request.
+func (b *CircuitBreaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	if b.failures++; b.failures >= b.Threshold {
+		b.openUntil = time.Now().Add(b.Cooldown)
+	}
+}
+
+// Release ends a request allowed by Allow without an outcome, e.g. a canceled one, so that another
+// trial can be let through.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
@@ -3,7 +3,9 @@ package config
 
 import (
 	"errors"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/creasty/defaults"
 )
@@ -15,7 +17,7 @@ type Configuration struct {
 }
 
 // New returns a new Configuration instance from the environment variables
-func New() (*Configuration, error) {
+func New(opts ...Option) (*Configuration, error) {
 	privateKey := os.Getenv("IMAGEKIT_PRIVATE_KEY")
 	publicKey := os.Getenv("IMAGEKIT_PUBLIC_KEY")
 	endpointUrl := os.Getenv("IMAGEKIT_ENDPOINT_URL")
@@ -29,11 +31,11 @@ func New() (*Configuration, error) {
 		return nil, errors.New("IMAGEKIT_ENDPOINT_URL envvar not set")
 	}
 
-	return NewFromParams(privateKey, publicKey, endpointUrl), nil
+	return NewFromParams(privateKey, publicKey, endpointUrl, opts...), nil
 }
 
 // NewFromParams returns a new Configuration instance from the provided keys and endpointUrl.
-func NewFromParams(privateKey string, publicKey string, endpointUrl string) *Configuration {
+func NewFromParams(privateKey string, publicKey string, endpointUrl string, opts ...Option) *Configuration {
 	cloudConf := Cloud{
 		PrivateKey:  privateKey,
 		PublicKey:   publicKey,
@@ -43,8 +45,57 @@ func NewFromParams(privateKey string, publicKey string, endpointUrl string) *Con
 	var api = API{}
 	defaults.Set(&api)
 
-	return &Configuration{
+	cfg := &Configuration{
 		Cloud: cloudConf,
 		API:   api,
 	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// Redacted returns a copy of the configuration with the private key and other credentials masked.
+// It is safe to log or share, e.g. in support tickets.
+func (c Configuration) Redacted() Configuration {
+	c.Cloud.PrivateKey = mask(c.Cloud.PrivateKey)
+	c.API.Authorization = mask(c.API.Authorization)
+
+	if c.API.Headers != nil {
+		headers := http.Header{}
+		for key, values := range c.API.Headers {
+			for _, v := range values {
+				if isSecretHeader(key) {
+					v = mask(v)
+				}
+				headers.Add(key, v)
+			}
+		}
+		c.API.Headers = headers
+	}
+
+	return c
+}
+
+func mask(s string) string {
+	switch {
+	case s == "":
+		return ""
+	case strings.HasPrefix(s, "private_"):
+		return "private_****"
+	}
+	return "****"
+}
+
+func isSecretHeader(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, word := range []string{"auth", "token", "key", "secret", "cookie"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
 }
@@ -1,8 +1,10 @@
 package config_test
 
 import (
+	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/imagekit-developer/imagekit-go/config"
 	"github.com/stretchr/testify/assert"
@@ -58,3 +60,65 @@ func TestConfiguration_CreateInstance(t *testing.T) {
 	assert.Equal(t, "private", c.Cloud.PrivateKey)
 	assert.Equal(t, "public", c.Cloud.PublicKey)
 }
+
+func TestConfiguration_Options(t *testing.T) {
+	c := config.NewFromParams("private", "public", "https://example/nature")
+
+	assert.Equal(t, 60*time.Second, c.API.EffectiveTimeout())
+	assert.Equal(t, 10*time.Minute, c.API.EffectiveUploadTimeout())
+
+	c = config.NewFromParams("private", "public", "https://example/nature",
+		config.WithTimeout(5*time.Second),
+		config.WithUploadTimeout(time.Hour),
+	)
+
+	assert.Equal(t, 5*time.Second, c.API.EffectiveTimeout())
+	assert.Equal(t, time.Hour, c.API.EffectiveUploadTimeout())
+
+	c = config.NewFromParams("private", "public", "https://example/nature",
+		config.WithRequestTimeout(3*time.Second),
+		config.WithHTTPClient(nil),
+	)
+
+	assert.Equal(t, 3*time.Second, c.API.EffectiveTimeout())
+	assert.Equal(t, 3*time.Second, c.API.EffectiveUploadTimeout())
+	assert.Equal(t, config.HTTPClient(http.DefaultClient), c.API.HTTPClient)
+}
+
+func TestConfiguration_DeprecatedTimeouts(t *testing.T) {
+	c := config.NewFromParams("private", "public", "https://example/nature")
+
+	c.API.Timeout = 30
+	c.API.UploadTimeout = 120
+
+	assert.Equal(t, 30*time.Second, c.API.EffectiveTimeout())
+	assert.Equal(t, 2*time.Minute, c.API.EffectiveUploadTimeout())
+
+	config.WithTimeout(5 * time.Second)(c)
+	config.WithUploadTimeout(0)(c)
+
+	assert.Equal(t, 5*time.Second, c.API.EffectiveTimeout())
+	assert.Equal(t, time.Duration(0), c.API.EffectiveUploadTimeout())
+}
+
+func TestConfiguration_Redacted(t *testing.T) {
+	c := config.NewFromParams("private_secret", "public_", "https://ik.imagekit.io/test/",
+		config.WithHeaders(http.Header{
+			"X-Gateway-Token":  []string{"gw-token"},
+			"X-Request-Source": []string{"cli"},
+		}),
+		config.WithAuthorization("Bearer token"),
+	)
+
+	r := c.Redacted()
+
+	assert.Equal(t, "private_****", r.Cloud.PrivateKey)
+	assert.Equal(t, "public_", r.Cloud.PublicKey)
+	assert.Equal(t, "https://ik.imagekit.io/test/", r.Cloud.UrlEndpoint)
+	assert.Equal(t, "****", r.API.Authorization)
+	assert.Equal(t, "****", r.API.Headers.Get("X-Gateway-Token"))
+	assert.Equal(t, "cli", r.API.Headers.Get("X-Request-Source"))
+
+	assert.Equal(t, "private_secret", c.Cloud.PrivateKey)
+	assert.Equal(t, "gw-token", c.API.Headers.Get("X-Gateway-Token"))
+}
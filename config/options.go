@@ -0,0 +1,216 @@
+package config
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option modifies the Configuration.
+type Option func(*Configuration)
+
+// WithTimeout sets the timeout for management (media and metadata) api calls. Zero disables it.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Configuration) {
+		c.API.RequestTimeout, c.API.Timeout = d, 0
+	}
+}
+
+// WithRequestTimeout sets the timeout of every api call, management and upload, to d. Like
+// WithTimeout and WithUploadTimeout it only applies when the context of the call has no deadline.
+// Zero disables it.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Configuration) {
+		c.API.RequestTimeout, c.API.Timeout = d, 0
+		c.API.UploadRequestTimeout, c.API.UploadTimeout = d, 0
+	}
+}
+
+// WithUploadTimeout sets the timeout for upload api calls. Zero disables it.
+func WithUploadTimeout(d time.Duration) Option {
+	return func(c *Configuration) {
+		c.API.UploadRequestTimeout, c.API.UploadTimeout = d, 0
+	}
+}
+
+// WithHeaders adds headers to every request, e.g. a token required by a gateway. They never
+// replace headers set by the SDK, including Authorization; use WithAuthorization for that.
+func WithHeaders(h http.Header) Option {
+	return func(c *Configuration) {
+		if c.API.Headers == nil {
+			c.API.Headers = http.Header{}
+		}
+
+		for key, values := range h {
+			for _, v := range values {
+				c.API.Headers.Add(key, v)
+			}
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request, e.g. to identify the traffic of an
+// application. It takes precedence over a User-Agent given to WithHeaders. By default the header is
+// imagekit-go followed by the version of the module when known.
+func WithUserAgent(ua string) Option {
+	return func(c *Configuration) {
+		c.API.UserAgent = ua
+	}
+}
+
+// WithAuthorization replaces the basic auth Authorization header computed from the private key
+// with value. It is only needed when a gateway in front of ImageKit expects a different scheme.
+func WithAuthorization(value string) Option {
+	return func(c *Configuration) {
+		c.API.Authorization = value
+	}
+}
+
+// WithTagNormalization trims tags and removes empty and duplicate ones before they are sent by
+// AddTags, RemoveTags and Upload. Tags are also lowercased when lowercase is true.
+func WithTagNormalization(lowercase bool) Option {
+	return func(c *Configuration) {
+		c.API.NormalizeTags = true
+		c.API.LowercaseTags = lowercase
+	}
+}
+
+// WithUploadRetries sets the number of retries of uploads, which replaces MaxRetries for upload
+// requests and retries them although they are POST requests. Retries share the delays and retry
+// policy of WithRetries, and attempts never multiply: an upload is sent at most n+1 times.
+func WithUploadRetries(n int) Option {
+	return func(c *Configuration) {
+		c.API.UploadRetries = n
+	}
+}
+
+// WithUploadRateLimit throttles upload request bodies to bytesPerSecond, e.g. for background sync
+// jobs which should not saturate the uplink. Zero disables throttling.
+func WithUploadRateLimit(bytesPerSecond int64) Option {
+	return func(c *Configuration) {
+		c.API.UploadRateLimit = bytesPerSecond
+	}
+}
+
+// WithFailover adds alternative management api base urls. A request failing with a connection
+// error is sent to each of them in order until one responds. Responses, including 4xx and 5xx,
+// never cause a failover.
+func WithFailover(prefixes ...string) Option {
+	return func(c *Configuration) {
+		c.API.Failover = append(c.API.Failover, prefixes...)
+	}
+}
+
+// WithUploadFailover adds alternative upload api base urls, see WithFailover.
+func WithUploadFailover(prefixes ...string) Option {
+	return func(c *Configuration) {
+		c.API.UploadFailover = append(c.API.UploadFailover, prefixes...)
+	}
+}
+
+// WithUseUniqueFileName sets the useUniqueFileName of uploads which leave it nil. An upload can
+// still override it either way by setting UploadParam.UseUniqueFileName.
+func WithUseUniqueFileName(unique bool) Option {
+	return func(c *Configuration) {
+		c.API.UseUniqueFileName = &unique
+	}
+}
+
+// WithRequestMutator calls mutate with every request after the SDK has built it, including its
+// headers and authorization, and before it is sent, e.g. to sign it for a corporate proxy or add
+// tracing headers. An error returned by mutate aborts the request and is returned to the caller.
+func WithRequestMutator(mutate func(*http.Request) error) Option {
+	return func(c *Configuration) {
+		c.API.RequestMutator = mutate
+	}
+}
+
+// WithRetries retries requests failing with a connection error, 429 or 5xx status up to n times.
+// The delay starts at base and doubles on each retry up to max, with random jitter. A Retry-After
+// header sent with the response is used instead, also capped at max. Only GET, HEAD, PUT and
+// DELETE requests are retried unless WithRetryNonIdempotent is set, and never when the request body
+// can not be read again.
+func WithRetries(n int, base time.Duration, max time.Duration) Option {
+	return func(c *Configuration) {
+		c.API.MaxRetries = n
+		c.API.RetryBaseDelay = base
+		c.API.RetryMaxDelay = max
+	}
+}
+
+// WithRetryNonIdempotent allows WithRetries to retry POST and PATCH requests as well, which may
+// apply the request twice when only its response was lost.
+func WithRetryNonIdempotent() Option {
+	return func(c *Configuration) {
+		c.API.RetryNonIdempotent = true
+	}
+}
+
+// WithRetryPolicy replaces the policy deciding which responses and errors WithRetries retries,
+// api.DefaultRetryPolicy by default. policy is called with the response or the error of the
+// request, e.g. to also retry a 400 known to be transient:
+//
+//	config.WithRetryPolicy(func(resp *http.Response, err error) bool {
+//		return api.DefaultRetryPolicy(resp, err) || resp.Header.Get("X-Transient") != ""
+//	})
+//
+// Cancelled requests and, unless WithRetryNonIdempotent is set, POST and PATCH requests are never
+// retried.
+func WithRetryPolicy(policy func(resp *http.Response, err error) bool) Option {
+	return func(c *Configuration) {
+		c.API.RetryPolicy = policy
+	}
+}
+
+// WithQueryTransformationFallback makes Url put transformations in the tr query parameter instead
+// of the path when the file path contains a character which conflicts with path transformations or
+// needs escaping: ':', ',', '?', '#' or '%'. Other urls are not affected.
+func WithQueryTransformationFallback() Option {
+	return func(c *Configuration) {
+		c.API.QueryTransformationFallback = true
+	}
+}
+
+// WithVerboseErrors makes api errors include the method, url and body of the request and the status
+// and body of the response in their message, with credentials redacted. Bodies are cut at 16 KB.
+// It is meant for development; keep it off in production to avoid leaking data into logs.
+func WithVerboseErrors(enabled bool) Option {
+	return func(c *Configuration) {
+		c.API.VerboseErrors = enabled
+	}
+}
+
+// WithFileTypeTransformations sets default transformations for urls of files of fileType, e.g.
+// "image", built by FileURL. They are applied before the transformations of the url, so
+//
+//	config.WithFileTypeTransformations("image", map[string]any{"format": "auto", "quality": "auto"})
+//
+// serves images as f-auto,q-auto while other files such as PDFs and videos are left unchanged.
+func WithFileTypeTransformations(fileType string, transformations ...map[string]any) Option {
+	return func(c *Configuration) {
+		if c.API.FileTypeTransformations == nil {
+			c.API.FileTypeTransformations = map[string][]map[string]any{}
+		}
+		c.API.FileTypeTransformations[fileType] = transformations
+	}
+}
+
+// WithCircuitBreaker makes requests fail fast with api.ErrCircuitOpen after threshold consecutive
+// requests failed with a connection error or 5xx status, until cooldown has passed. The breaker is
+// shared by the media, metadata and upload apis of a client.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Configuration) {
+		c.API.CircuitBreaker = &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+	}
+}
+
+// WithHTTPClient makes the media, metadata and upload apis send their requests with client, e.g. an
+// *http.Client with a proxy or instrumented transport. Timeouts, retries and failover of the SDK
+// still apply on top of it. A nil client falls back to http.DefaultClient.
+func WithHTTPClient(client HTTPClient) Option {
+	return func(c *Configuration) {
+		c.API.HTTPClient = client
+		if client == nil {
+			c.API.HTTPClient = http.DefaultClient
+		}
+	}
+}
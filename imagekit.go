@@ -1,15 +1,20 @@
 package imagekit
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
-	"log"
-	"net/http"
+	"errors"
+	"fmt"
+	neturl "net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/imagekit-developer/imagekit-go/api"
 	"github.com/imagekit-developer/imagekit-go/api/media"
 	"github.com/imagekit-developer/imagekit-go/api/metadata"
 	"github.com/imagekit-developer/imagekit-go/api/uploader"
@@ -25,6 +30,9 @@ type ImageKit struct {
 	Metadata *metadata.API
 	Uploader *uploader.API
 	getToken func() string
+
+	endpointMu      sync.Mutex
+	accountEndpoint string // derived by AccountURLEndpoint, guarded by endpointMu
 }
 
 // NewParams is a struct to define parameters to imagekit
@@ -35,8 +43,8 @@ type NewParams struct {
 }
 
 // New returns ImageKit object from environment variables
-func New() (*ImageKit, error) {
-	cfg, err := config.New()
+func New(opts ...config.Option) (*ImageKit, error) {
+	cfg, err := config.New(opts...)
 
 	if err != nil {
 		return nil, err
@@ -52,16 +60,16 @@ func getToken() string {
 }
 
 // NewFromParams returns a new ImageKit object from provided parameters
-func NewFromParams(params NewParams) *ImageKit {
+func NewFromParams(params NewParams, opts ...config.Option) *ImageKit {
 	return NewFromConfiguration(
-		config.NewFromParams(params.PrivateKey, params.PublicKey, params.UrlEndpoint),
+		config.NewFromParams(params.PrivateKey, params.PublicKey, params.UrlEndpoint, opts...),
 	)
 }
 
 // NewFromConfiguration returns new ImageKit object from configuration object
 func NewFromConfiguration(cfg *config.Configuration) *ImageKit {
 	log := logger.New()
-	client := &http.Client{}
+	client := api.NewClient(cfg)
 
 	return &ImageKit{
 		Config: *cfg,
@@ -91,10 +99,13 @@ type SignTokenParam struct {
 	unix    func() int64
 }
 
+// SignedToken holds the authentication parameters of client-side uploads. It marshals to the
+// token, expire and signature fields expected by the ImageKit frontend SDKs, so it can be returned
+// as is by an authentication endpoint.
 type SignedToken struct {
-	Token     string
-	Expires   int64
-	Signature string
+	Token     string `json:"token"`
+	Expires   int64  `json:"expire"`
+	Signature string `json:"signature"`
 }
 
 // SignToken signs given token and expiration timestamp with private key
@@ -114,9 +125,67 @@ func (ik *ImageKit) SignToken(param SignTokenParam) SignedToken {
 		param.Expires = e + DefaultTokenExpire
 	}
 
-	log.Println(param)
 	mac := hmac.New(sha1.New, []byte(ik.Config.Cloud.PrivateKey))
 	mac.Write([]byte(param.Token + strconv.FormatInt(param.Expires, 10)))
 	signature := hex.EncodeToString(mac.Sum(nil))
 	return SignedToken{Token: param.Token, Expires: param.Expires, Signature: signature}
 }
+
+// AccountURLEndpoint returns the configured url endpoint. When none is configured the default
+// endpoint of the account is derived from the url of a media library file and cached, so call it
+// before generating urls without an explicit endpoint. The configuration is not changed.
+func (ik *ImageKit) AccountURLEndpoint(ctx context.Context) (string, error) {
+	if ik.Config.Cloud.UrlEndpoint != "" {
+		return ik.Config.Cloud.UrlEndpoint, nil
+	}
+
+	ik.endpointMu.Lock()
+	defer ik.endpointMu.Unlock()
+
+	if ik.accountEndpoint != "" {
+		return ik.accountEndpoint, nil
+	}
+
+	resp, err := ik.Media.Files(ctx, media.FilesParam{Type: media.ListFile, Limit: 1})
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Data) == 0 {
+		return "", errors.New("url endpoint can not be derived from an empty media library")
+	}
+
+	endpoint, err := fileEndpoint(resp.Data[0])
+	if err != nil {
+		return "", err
+	}
+
+	ik.accountEndpoint = endpoint
+
+	return ik.accountEndpoint, nil
+}
+
+// fileEndpoint returns the url endpoint of the url of file, i.e. its url without the file path.
+func fileEndpoint(file media.File) (string, error) {
+	u, err := neturl.Parse(file.Url)
+	if err != nil || file.FilePath == "" || !strings.HasSuffix(u.Path, file.FilePath) {
+		return "", fmt.Errorf("url endpoint can not be derived from file url %s", file.Url)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, file.FilePath) + "/"
+	u.RawPath, u.RawQuery, u.Fragment = "", "", ""
+
+	return u.String(), nil
+}
+
+// urlEndpoint returns the configured url endpoint, or the one derived by AccountURLEndpoint.
+func (ik *ImageKit) urlEndpoint() string {
+	if ik.Config.Cloud.UrlEndpoint != "" {
+		return ik.Config.Cloud.UrlEndpoint
+	}
+
+	ik.endpointMu.Lock()
+	defer ik.endpointMu.Unlock()
+
+	return ik.accountEndpoint
+}
@@ -1,16 +1,38 @@
 package imagekit
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	neturl "net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/imagekit-developer/imagekit-go/api"
+	"github.com/imagekit-developer/imagekit-go/api/media"
+	"github.com/imagekit-developer/imagekit-go/api/uploader"
+	"github.com/imagekit-developer/imagekit-go/config"
 	"github.com/imagekit-developer/imagekit-go/logger"
+	iktest "github.com/imagekit-developer/imagekit-go/test"
 	ikurl "github.com/imagekit-developer/imagekit-go/url"
 )
 
@@ -27,6 +49,28 @@ func init() {
 	os.Setenv("IMAGEKIT_ENDPOINT_URL", "https://ik.imagekit.io/test/")
 }
 
+func Test_WithHTTPClient(t *testing.T) {
+	rec := &iktest.RecordingTransport{Body: `{}`}
+
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/test/",
+	}, config.WithHTTPClient(rec))
+
+	if ik.Media.Client != rec || ik.Metadata.Client != rec || ik.Uploader.Client != rec {
+		t.Fatal("expected the configured client in all apis")
+	}
+
+	if _, err := ik.Metadata.FromFile(context.Background(), "file_id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if req, ok := rec.Last(); !ok || req.URL != "https://api.imagekit.io/v1/files/file_id/metadata" {
+		t.Errorf("expected request sent with the configured client, got %v", req)
+	}
+}
+
 func Test_New(t *testing.T) {
 	var ik any
 	ik, err := New()
@@ -252,6 +296,25 @@ func TestUrl(t *testing.T) {
 				},
 			},
 			url: "https://ik.imagekit.io/test/tr:oi-test2_hBIIEweBy.gif,ox-100,oy-110,oh-200,ow-200,oib-4_blue,oidpr-0.2,oiq-80,oic-at_max,oix-100,oiy-20,oit-false/default-image.jpg",
+		}, {
+			name: "blurred-transparent-layer",
+			params: ikurl.UrlParam{
+				Path: "default-image.jpg",
+				Transformations: []map[string]any{
+					{
+						"width": 300,
+						"layer": ikurl.Layer{
+							Type:  ikurl.ImageLayer,
+							Input: "/logos/logo.png",
+							Transformations: map[string]any{
+								"blur":    10,
+								"opacity": 50,
+							},
+						},
+					},
+				},
+			},
+			url: "https://ik.imagekit.io/test/tr:w-300,l-image,i-logos@@logo.png,bl-10,o-50,l-end/default-image.jpg",
 		},
 	}
 
@@ -280,6 +343,401 @@ func TestUrl(t *testing.T) {
 
 }
 
+func TestUrl_Layer(t *testing.T) {
+	url, err := imgkit.Url(ikurl.UrlParam{
+		Path: "default-image.jpg",
+		Transformations: []map[string]any{
+			{
+				"layer": ikurl.Layer{
+					Type:            ikurl.TextLayer,
+					Input:           "Hello",
+					Transformations: map[string]any{"layerX": 10},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if url != "https://ik.imagekit.io/test/tr:l-text,i-Hello,lx-10,l-end/default-image.jpg" {
+		t.Errorf("unexpected url: %s", url)
+	}
+
+	// a comma would start the next parameter and a slash end the transformation
+	url, err = imgkit.Url(ikurl.UrlParam{
+		Path: "default-image.jpg",
+		Transformations: []map[string]any{
+			{"layer": ikurl.Layer{Type: ikurl.TextLayer, Input: "Hello, world/x"}},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if url != "https://ik.imagekit.io/test/tr:l-text,ie-SGVsbG8sIHdvcmxkL3g=,l-end/default-image.jpg" {
+		t.Errorf("unexpected url: %s", url)
+	}
+
+	url, err = imgkit.Url(ikurl.UrlParam{
+		Path: "default-image.jpg",
+		Transformations: []map[string]any{
+			{"layer": ikurl.Layer{Type: ikurl.TextLayer, Input: "Hi?"}},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the slash of the base64 text is escaped so the tr segment is not ended
+	if url != "https://ik.imagekit.io/test/tr:l-text,ie-SGk%2F,l-end/default-image.jpg" {
+		t.Errorf("unexpected url: %s", url)
+	}
+
+	var invalid = map[string]map[string]any{
+		"layer only at base":  {"opacity": 50},
+		"missing layer input": {"layer": ikurl.Layer{Type: ikurl.ImageLayer}},
+		"nested layer":        {"layer": ikurl.Layer{Type: ikurl.ImageLayer, Input: "a.png", Transformations: map[string]any{"layer": ikurl.Layer{Type: ikurl.TextLayer, Input: "b"}}}},
+	}
+
+	for name, tr := range invalid {
+		if _, err := imgkit.Url(ikurl.UrlParam{Path: "a.jpg", Transformations: []map[string]any{tr}}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestUrl_Progressive(t *testing.T) {
+	var cases = map[string]struct {
+		tr         map[string]any
+		expected   string
+		shouldFail bool
+	}{
+		"progressive": {
+			tr:       map[string]any{"progressive": true},
+			expected: "pr-true",
+		},
+		"progressive jpeg": {
+			tr:       map[string]any{"progressive": true, "format": "jpg"},
+			expected: "f-jpg,pr-true",
+		},
+		"progressive auto format": {
+			tr:       map[string]any{"progressive": "true", "format": "auto"},
+			expected: "f-auto,pr-true",
+		},
+		"not progressive png": {
+			tr:       map[string]any{"progressive": false, "format": "png"},
+			expected: "f-png,pr-false",
+		},
+		"progressive png": {
+			tr:         map[string]any{"progressive": true, "format": "png"},
+			shouldFail: true,
+		},
+		"progressive webp": {
+			tr:         map[string]any{"progressive": true, "format": ikurl.FormatWebP},
+			shouldFail: true,
+		},
+		"progressive uppercase jpg": {
+			tr:         map[string]any{"progressive": true, "format": "JPG"},
+			shouldFail: true,
+		},
+		"interlace": {
+			tr:       map[string]any{"interlace": true},
+			expected: "il-true",
+		},
+		"interlace png": {
+			tr:       map[string]any{"interlace": true, "format": ikurl.FormatPNG},
+			expected: "f-png,il-true",
+		},
+		"interlace gif": {
+			tr:       map[string]any{"interlace": "true", "format": "gif"},
+			expected: "f-gif,il-true",
+		},
+		"interlace auto format": {
+			tr:       map[string]any{"interlace": true, "format": ikurl.FormatAuto},
+			expected: "f-auto,il-true",
+		},
+		"not interlace jpg": {
+			tr:       map[string]any{"interlace": false, "format": "jpg"},
+			expected: "f-jpg,il-false",
+		},
+		"interlace jpg": {
+			tr:         map[string]any{"interlace": true, "format": "jpg"},
+			shouldFail: true,
+		},
+		"interlace webp": {
+			tr:         map[string]any{"interlace": true, "format": ikurl.FormatWebP},
+			shouldFail: true,
+		},
+		"progressive and interlace": {
+			tr:         map[string]any{"progressive": true, "interlace": true},
+			shouldFail: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := imgkit.Url(ikurl.UrlParam{
+				Path:            "default-image.jpg",
+				Transformations: []map[string]any{tc.tr},
+				Strict:          true,
+			})
+
+			if tc.shouldFail {
+				if err == nil {
+					t.Errorf("expected error, got %s", url)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, tr := extractTransformation(t, url)
+			if strings.Join(tr, ",") != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, strings.Join(tr, ","))
+			}
+		})
+	}
+
+	if _, err := imgkit.Url(ikurl.UrlParam{
+		Path:            "default-image.jpg",
+		Transformations: []map[string]any{{"progressive": true, "format": "png"}, {"interlace": true, "format": "jpg"}},
+	}); err != nil {
+		t.Errorf("incompatible parameters should only fail in strict mode: %v", err)
+	}
+}
+
+func TestUrl_Format(t *testing.T) {
+	var formats = map[ikurl.Format]string{
+		ikurl.FormatAuto: "f-auto",
+		ikurl.FormatJPG:  "f-jpg",
+		ikurl.FormatJPEG: "f-jpeg",
+		ikurl.FormatPNG:  "f-png",
+		ikurl.FormatWebP: "f-webp",
+		ikurl.FormatAVIF: "f-avif",
+		ikurl.FormatGIF:  "f-gif",
+		ikurl.FormatSVG:  "f-svg",
+		ikurl.FormatMP4:  "f-mp4",
+		ikurl.FormatWebM: "f-webm",
+		ikurl.FormatOrig: "f-orig",
+	}
+
+	for format, expected := range formats {
+		url, err := imgkit.Url(ikurl.UrlParam{
+			Path:            "default-image.jpg",
+			Transformations: []map[string]any{{"format": format}},
+			Strict:          true,
+		})
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+
+		if url != "https://ik.imagekit.io/test/tr:"+expected+"/default-image.jpg" {
+			t.Errorf("%s: unexpected url %s", format, url)
+		}
+	}
+
+	for _, invalid := range []any{ikurl.Format("bmp"), "tiff", "PNG"} {
+		if _, err := imgkit.Url(ikurl.UrlParam{
+			Path:            "default-image.jpg",
+			Transformations: []map[string]any{{"format": invalid}},
+			Strict:          true,
+		}); err == nil {
+			t.Errorf("%v: expected unsupported format error", invalid)
+		}
+	}
+}
+
+func TestUrl_PathEscaping(t *testing.T) {
+	var tr = []map[string]any{{"width": 100}}
+
+	var cases = map[string]struct {
+		params ikurl.UrlParam
+		url    string
+	}{
+		"leading slash": {
+			params: ikurl.UrlParam{Path: "/photos/a.jpg"},
+			url:    "https://ik.imagekit.io/test/photos/a.jpg",
+		},
+		"space": {
+			params: ikurl.UrlParam{Path: "/photos/a b.jpg", Transformations: tr},
+			url:    "https://ik.imagekit.io/test/tr:w-100/photos/a%20b.jpg",
+		},
+		"already escaped": {
+			params: ikurl.UrlParam{Path: "photos/a%20b.jpg"},
+			url:    "https://ik.imagekit.io/test/photos/a%20b.jpg",
+		},
+		"query and fragment delimiters": {
+			params: ikurl.UrlParam{Path: "photos/a?b#c.jpg", Transformations: tr},
+			url:    "https://ik.imagekit.io/test/tr:w-100/photos/a%3Fb%23c.jpg",
+		},
+		"percent sign": {
+			params: ikurl.UrlParam{Path: "photos/100%.jpg", Transformations: tr, TransformationPosition: ikurl.QUERY},
+			url:    "https://ik.imagekit.io/test/photos/100%25.jpg?tr=w-100",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := imgkit.Url(tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if url != tc.url {
+				t.Errorf("unexpected url: %s", url)
+			}
+		})
+	}
+}
+
+func TestUrl_InvalidParams(t *testing.T) {
+	if _, err := imgkit.Url(ikurl.UrlParam{Path: "default-image.jpg", Src: "https://ik.imagekit.io/test/default-image.jpg"}); err == nil {
+		t.Error("expected error for path and src")
+	}
+
+	ik := NewFromParams(NewParams{PublicKey: "public_", UrlEndpoint: "https://ik.imagekit.io/test/"})
+
+	if _, err := ik.Url(ikurl.UrlParam{Path: "default-image.jpg", Signed: true}); err == nil {
+		t.Error("expected error for signed url without private key")
+	}
+
+	if _, err := ik.Url(ikurl.UrlParam{Path: "default-image.jpg"}); err != nil {
+		t.Errorf("unexpected error for unsigned url: %v", err)
+	}
+}
+
+func TestUrl_QueryTransformationFallback(t *testing.T) {
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/test/",
+	}, config.WithQueryTransformationFallback())
+
+	var tr = []map[string]any{{"width": 100}}
+
+	var cases = map[string]struct {
+		ik   *ImageKit
+		path string
+		url  string
+	}{
+		"plain path": {ik, "photos/a.jpg", "https://ik.imagekit.io/test/tr:w-100/photos/a.jpg"},
+		"colon":      {ik, "photos/tr:legacy.jpg", "https://ik.imagekit.io/test/photos/tr:legacy.jpg?tr=w-100"},
+		"comma":      {ik, "photos/a,b.jpg", "https://ik.imagekit.io/test/photos/a,b.jpg?tr=w-100"},
+		"escaped":    {ik, "photos/a#1.jpg", "https://ik.imagekit.io/test/photos/a%231.jpg?tr=w-100"},
+		"disabled":   {imgkit, "photos/a,b.jpg", "https://ik.imagekit.io/test/tr:w-100/photos/a,b.jpg"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := tc.ik.Url(ikurl.UrlParam{Path: tc.path, Transformations: tr})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if url != tc.url {
+				t.Errorf("unexpected url: %s", url)
+			}
+		})
+	}
+}
+
+func TestUrl_GenerativeFill(t *testing.T) {
+	var cases = map[string]struct {
+		tr       map[string]any
+		expected string
+	}{
+		"without prompt": {
+			tr:       map[string]any{"generativeFill": ikurl.GenerativeFill{Width: 1200, Height: 600}},
+			expected: "https://ik.imagekit.io/test/tr:w-1200,h-600,cm-pad_resize,bg-genfill/default-image.jpg",
+		},
+		"prompt with spaces": {
+			tr:       map[string]any{"generativeFill": ikurl.GenerativeFill{Width: 1200, Height: 600, Prompt: "snowy mountains"}},
+			expected: "https://ik.imagekit.io/test/tr:w-1200,h-600,cm-pad_resize,bg-genfill-prompt-snowy%20mountains/default-image.jpg",
+		},
+		"prompt with special characters": {
+			tr:       map[string]any{"generativeFill": ikurl.GenerativeFill{Width: 800, Height: 800, Prompt: "sea, sky & sun?"}},
+			expected: "https://ik.imagekit.io/test/tr:w-800,h-800,cm-pad_resize,bg-genfill-prompte-c2VhLCBza3kgJiBzdW4%2F/default-image.jpg",
+		},
+		"variation": {
+			tr:       map[string]any{"effectGenVariation": "-"},
+			expected: "https://ik.imagekit.io/test/tr:e-genvar/default-image.jpg",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := imgkit.Url(ikurl.UrlParam{
+				Path:            "default-image.jpg",
+				Transformations: []map[string]any{tc.tr},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if url != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, url)
+			}
+		})
+	}
+
+	if _, err := imgkit.Url(ikurl.UrlParam{
+		Path:            "default-image.jpg",
+		Transformations: []map[string]any{{"generativeFill": ikurl.GenerativeFill{Width: 1200}}},
+	}); err == nil {
+		t.Error("expected error for missing height")
+	}
+
+	// in the query the prompt is escaped once, along with the other query parameters
+	var queryCases = map[string]struct {
+		params   ikurl.UrlParam
+		prompt   string
+		expected string
+	}{
+		"query position": {
+			params:   ikurl.UrlParam{Path: "default-image.jpg", TransformationPosition: ikurl.QUERY},
+			prompt:   "snowy mountains",
+			expected: "w-1200,h-600,cm-pad_resize,bg-genfill-prompt-snowy mountains",
+		},
+		"src": {
+			params:   ikurl.UrlParam{Src: "https://ik.imagekit.io/test/default-image.jpg"},
+			prompt:   "snowy mountains",
+			expected: "w-1200,h-600,cm-pad_resize,bg-genfill-prompt-snowy mountains",
+		},
+		"src with special characters": {
+			params:   ikurl.UrlParam{Src: "https://ik.imagekit.io/test/default-image.jpg"},
+			prompt:   "sea, sky & sun?",
+			expected: "w-1200,h-600,cm-pad_resize,bg-genfill-prompte-c2VhLCBza3kgJiBzdW4/",
+		},
+	}
+
+	for name, tc := range queryCases {
+		t.Run(name, func(t *testing.T) {
+			tc.params.Transformations = []map[string]any{{"generativeFill": ikurl.GenerativeFill{Width: 1200, Height: 600, Prompt: tc.prompt}}}
+
+			url, err := imgkit.Url(tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			u, err := neturl.Parse(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tr := u.Query().Get("tr"); tr != tc.expected {
+				t.Errorf("expected: %s\ngot: %s (%s)", tc.expected, tr, url)
+			}
+		})
+	}
+}
+
 func extractTransformation(t *testing.T, url string) (urlResult string, trResult []string) {
 	re := regexp.MustCompile("tr:(.+)/")
 	m := re.FindStringSubmatch(url)
@@ -355,4 +813,1112 @@ func Test_SignToken(t *testing.T) {
 		})
 	}
 
+	data, _ := json.Marshal(imgkit.SignToken(SignTokenParam{Token: "31c468de-520a-4dc1-8868-de1e0fb93a7b", Expires: 1655379249}))
+	if string(data) != `{"token":"31c468de-520a-4dc1-8868-de1e0fb93a7b","expire":1655379249,"signature":"ed6f1aadeec33eb3509c0576e6a05100861c64c5"}` {
+		t.Errorf("unexpected json: %s", data)
+	}
+}
+
+func Test_ShareURL(t *testing.T) {
+	params := ikurl.UrlParam{
+		Path: "default-image.jpg",
+		UnixTime: func() int64 {
+			return 1653775828
+		},
+	}
+
+	url, err := imgkit.ShareURL(params, 100*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://ik.imagekit.io/test/default-image.jpg?ik-t=1653775928&ik-s=48842eca663c6895331331db6c90f262c601f4e8"
+	if url != expected {
+		t.Errorf("expected url: %s\ngot: %s", expected, url)
+	}
+
+	if _, err = imgkit.ShareURL(params, 0); err == nil {
+		t.Error("expected error for zero ttl")
+	}
+
+	noKey := NewFromParams(NewParams{PublicKey: "public_", UrlEndpoint: "https://ik.imagekit.io/test/"})
+	if _, err = noKey.ShareURL(params, time.Minute); err == nil {
+		t.Error("expected error for missing private key")
+	}
+}
+
+func Test_SignedURLSignature(t *testing.T) {
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_key_test",
+		PublicKey:   "public_key_test",
+		UrlEndpoint: "https://ik.imagekit.io/test_url_endpoint",
+	})
+
+	var now = func() int64 { return 1700000000 }
+	var tr = []map[string]any{{"width": 100}}
+
+	// signatures are HMAC-SHA1 of the url without endpoint followed by the expiry timestamp
+	var cases = map[string]struct {
+		params ikurl.UrlParam
+		url    string
+	}{
+		"no expiry": {
+			params: ikurl.UrlParam{Path: "test-signed-url.png", Signed: true},
+			url:    "https://ik.imagekit.io/test_url_endpoint/test-signed-url.png?ik-s=1cc2e8423ea35b79cc27c9fd6c8d331b5e909894",
+		},
+		"path transformation": {
+			params: ikurl.UrlParam{Path: "test-signed-url.png", Transformations: tr, Signed: true, ExpireSeconds: 600, UnixTime: now},
+			url:    "https://ik.imagekit.io/test_url_endpoint/tr:w-100/test-signed-url.png?ik-t=1700000600&ik-s=dfd01c5fde727f96d765f0cff1b3137a12e3b9a2",
+		},
+		"query transformation": {
+			params: ikurl.UrlParam{
+				Path:                   "test-signed-url.png",
+				Transformations:        tr,
+				TransformationPosition: ikurl.QUERY,
+				QueryParameters:        map[string]string{"v": "1"},
+				Signed:                 true,
+				ExpireSeconds:          600,
+				UnixTime:               now,
+			},
+			url: "https://ik.imagekit.io/test_url_endpoint/test-signed-url.png?tr=w-100&v=1&ik-t=1700000600&ik-s=99c1792a53683219d719edd3517c9011478de222",
+		},
+		"default image": {
+			params: ikurl.UrlParam{
+				Path:            "photos/item.jpg",
+				Transformations: []map[string]any{{"width": 300}, {"defaultImage": "/defaults/no image#1.jpg"}},
+				Signed:          true,
+				ExpireSeconds:   600,
+				UnixTime:        now,
+			},
+			url: "https://ik.imagekit.io/test_url_endpoint/tr:w-300:di-defaults@@no%20image%231.jpg/photos/item.jpg?ik-t=1700000600&ik-s=8dd92b4026903572baa37a2117130fc5c219f6ec",
+		},
+		"default image in query": {
+			params: ikurl.UrlParam{
+				Path:                   "photos/item.jpg",
+				Transformations:        []map[string]any{{"width": 300}, {"defaultImage": "/defaults/no image#1.jpg"}},
+				TransformationPosition: ikurl.QUERY,
+				Signed:                 true,
+				ExpireSeconds:          600,
+				UnixTime:               now,
+			},
+			url: "https://ik.imagekit.io/test_url_endpoint/photos/item.jpg?tr=w-300%3Adi-defaults%40%40no+image%231.jpg&ik-t=1700000600&ik-s=56292155a8b8a980e59f0491d5aedea6c8ec8b25",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := ik.Url(tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if url != tc.url {
+				t.Errorf("expected url: %s\ngot: %s", tc.url, url)
+			}
+
+			// urls with a fixed expiry in the past are expired but must have a valid signature
+			if err = ik.ValidateSignedURL(url); err != nil && err != ErrExpiredURL {
+				t.Errorf("signature does not validate: %v", err)
+			}
+		})
+	}
+}
+
+func Test_ValidateSignedURL(t *testing.T) {
+	valid, err := imgkit.Url(ikurl.UrlParam{
+		Path:            "default-image.jpg",
+		Transformations: []map[string]any{{"width": 400}},
+		QueryParameters: map[string]string{"v": "123"},
+		Signed:          true,
+		ExpireSeconds:   3600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = imgkit.ValidateSignedURL(valid); err != nil {
+		t.Errorf("valid url: %v", err)
+	}
+
+	tampered := strings.Replace(valid, "w-400", "w-800", 1)
+	if err = imgkit.ValidateSignedURL(tampered); err != ErrInvalidSignature {
+		t.Errorf("tampered url: expected ErrInvalidSignature, got %v", err)
+	}
+
+	expired, err := imgkit.Url(ikurl.UrlParam{
+		Path:          "default-image.jpg",
+		Signed:        true,
+		ExpireSeconds: 60,
+		UnixTime:      func() int64 { return time.Now().Add(-time.Hour).Unix() },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = imgkit.ValidateSignedURL(expired); err != ErrExpiredURL {
+		t.Errorf("expired url: expected ErrExpiredURL, got %v", err)
+	}
+
+	if err = imgkit.ValidateSignedURL("https://ik.imagekit.io/test/default-image.jpg"); err == nil {
+		t.Error("unsigned url: expected error")
+	}
+
+	custom, err := imgkit.Url(ikurl.UrlParam{
+		Path:        "default-image.jpg",
+		UrlEndpoint: "https://images.example.com/assets",
+		Signed:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = imgkit.ValidateSignedURL(custom); err == nil {
+		t.Error("url of another endpoint: expected error")
+	}
+
+	if err = imgkit.ValidateSignedURL(custom, "https://ik.imagekit.io/test/", "https://images.example.com/assets/"); err != nil {
+		t.Errorf("url of per-call endpoint: %v", err)
+	}
+
+	if err = imgkit.ValidateSignedURL(strings.Replace(custom, "default-image", "other-image", 1), "https://images.example.com/assets"); err != ErrInvalidSignature {
+		t.Errorf("tampered url of per-call endpoint: expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func Test_VersionedSignedURL(t *testing.T) {
+	url, err := imgkit.Url(ikurl.UrlParam{
+		Path:          "default-image.jpg",
+		Version:       "62a9c3ccd875ec6fd658c854",
+		Signed:        true,
+		ExpireSeconds: 100,
+		UnixTime:      func() int64 { return 4000000000 },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned := "https://ik.imagekit.io/test/default-image.jpg?ik-obj-version=62a9c3ccd875ec6fd658c854"
+	expected := unsigned + "&ik-t=4000000100&ik-s=" + imgkit.urlSignature("default-image.jpg?ik-obj-version=62a9c3ccd875ec6fd658c854", "4000000100")
+
+	if url != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, url)
+	}
+
+	if err = imgkit.ValidateSignedURL(url); err != nil {
+		t.Error(err)
+	}
+
+	other := strings.Replace(url, "62a9c3ccd875ec6fd658c854", "62a9c3ccd875ec6fd658c855", 1)
+	if err = imgkit.ValidateSignedURL(other); err != ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature for another version, got %v", err)
+	}
+}
+
+func TestUrl_AttachmentAndUpdatedAt(t *testing.T) {
+	var updatedAt = time.UnixMilli(1654873963613)
+
+	var cases = map[string]struct {
+		params ikurl.UrlParam
+		url    string
+	}{
+		"attachment": {
+			params: ikurl.UrlParam{Path: "default-image.jpg", Attachment: true},
+			url:    "https://ik.imagekit.io/test/default-image.jpg?ik-attachment=true",
+		},
+		"updated at": {
+			params: ikurl.UrlParam{Path: "default-image.jpg", UpdatedAt: updatedAt},
+			url:    "https://ik.imagekit.io/test/default-image.jpg?updatedAt=1654873963613",
+		},
+		"both with query transformation": {
+			params: ikurl.UrlParam{
+				Path:                   "default-image.jpg",
+				Attachment:             true,
+				UpdatedAt:              updatedAt,
+				Transformations:        []map[string]any{{"width": 100}},
+				TransformationPosition: ikurl.QUERY,
+			},
+			url: "https://ik.imagekit.io/test/default-image.jpg?ik-attachment=true&tr=w-100&updatedAt=1654873963613",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := imgkit.Url(tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if url != tc.url {
+				t.Errorf("expected: %s\ngot: %s", tc.url, url)
+			}
+		})
+	}
+
+	// both are covered by the signature
+	url, err := imgkit.Url(ikurl.UrlParam{
+		Path:          "default-image.jpg",
+		Attachment:    true,
+		UpdatedAt:     updatedAt,
+		Signed:        true,
+		ExpireSeconds: 100,
+		UnixTime:      func() int64 { return 4000000000 },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = imgkit.ValidateSignedURL(url); err != nil {
+		t.Error(err)
+	}
+
+	inline := strings.Replace(url, "ik-attachment=true&", "", 1)
+	if err = imgkit.ValidateSignedURL(inline); err != ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature without attachment, got %v", err)
+	}
+}
+
+func Test_ParseTransformation(t *testing.T) {
+	var cases = map[string]ikurl.UrlParam{
+		"path": {
+			Path: "default-image.jpg",
+			Transformations: []map[string]any{
+				{
+					"width":        200,
+					"height":       "400",
+					"effectGray":   "-",
+					"defaultImage": "folder/default.jpg",
+					"overlayText":  "hello world",
+				}, {
+					"rotation":      90,
+					"effectSharpen": 40,
+					"raw":           "zz-1",
+				},
+			},
+		},
+		"query": {
+			Path:                   "default-image.jpg",
+			TransformationPosition: ikurl.QUERY,
+			Transformations: []map[string]any{
+				{"width": 100, "effectUSM": "2-2-0.8-0.024"},
+			},
+		},
+		"src": {
+			Src:             "https://ik.imagekit.io/test/default-image.jpg",
+			Transformations: []map[string]any{{"quality": 80}},
+			Signed:          true,
+			ExpireSeconds:   100,
+		},
+	}
+
+	for name, params := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := imgkit.Url(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := ikurl.ParseTransformation(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(parsed) != len(params.Transformations) {
+				t.Fatalf("expected %d transformations, got %d", len(params.Transformations), len(parsed))
+			}
+
+			for i, tr := range params.Transformations {
+				for k, v := range tr {
+					if fmt.Sprint(parsed[i][k]) != fmt.Sprint(v) {
+						t.Errorf("%s: expected %v, got %v", k, v, parsed[i][k])
+					}
+				}
+			}
+
+			params.Transformations = parsed
+			regenerated, err := imgkit.Url(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if again, _ := ikurl.ParseTransformation(regenerated); !cmp.Equal(again, parsed) {
+				t.Errorf("round trip mismatch\n%v\n%v", parsed, again)
+			}
+		})
+	}
+
+	if parsed, err := ikurl.ParseTransformation("https://ik.imagekit.io/test/default-image.jpg"); err != nil || len(parsed) != 0 {
+		t.Errorf("expected no transformations, got %v, %v", parsed, err)
+	}
+}
+
+func Test_LQIPURL(t *testing.T) {
+	var cases = map[string]struct {
+		params ikurl.UrlParam
+		opts   []LQIPOption
+		url    string
+		tr     []string
+	}{
+		"defaults": {
+			params: ikurl.UrlParam{Path: "default-image.jpg"},
+			url:    "https://ik.imagekit.io/test//default-image.jpg",
+			tr:     []string{"bl-10", "q-20", "w-20"},
+		},
+		"configured after transformations": {
+			params: ikurl.UrlParam{Path: "default-image.jpg", Transformations: []map[string]any{{"rotation": 90}}},
+			opts:   []LQIPOption{WithLQIPWidth(40), WithLQIPBlur(5), WithLQIPQuality(10)},
+			url:    "https://ik.imagekit.io/test//default-image.jpg",
+			tr:     []string{"rt-90", "bl-5", "q-10", "w-40"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := imgkit.LQIPURL(tc.params, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resultUrl, tr := extractTransformation(t, url)
+			if resultUrl != tc.url || !cmp.Equal(tr, tc.tr) {
+				t.Errorf("unexpected url: %s", url)
+			}
+		})
+	}
+
+	if _, err := imgkit.LQIPURL(ikurl.UrlParam{Path: "default-image.jpg"}, WithLQIPQuality(0)); err == nil {
+		t.Error("expected error for invalid quality")
+	}
+}
+
+func Test_DownloadURL(t *testing.T) {
+	var cases = map[string]struct {
+		body     string
+		signed   bool
+		endpoint string
+	}{
+		"public": {
+			body:   `{"fileId":"123","url":"https://ik.imagekit.io/test/public.jpg","filePath":"/public.jpg","isPrivateFile":false}`,
+			signed: false,
+		},
+		"private": {
+			body:     `{"fileId":"123","url":"https://ik.imagekit.io/test/private.jpg","filePath":"/private.jpg","isPrivateFile":true}`,
+			signed:   true,
+			endpoint: "https://ik.imagekit.io/test/",
+		},
+		"private on custom domain": {
+			body:     `{"fileId":"123","url":"https://cdn.example.com/x/docs/private.jpg","filePath":"/docs/private.jpg","isPrivateFile":true}`,
+			signed:   true,
+			endpoint: "https://cdn.example.com/x",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpTest := iktest.NewHttp(t)
+			ts := httptest.NewServer(httpTest.Handler(200, tc.body))
+			defer ts.Close()
+
+			ik := NewFromParams(NewParams{
+				PrivateKey:  "private_",
+				PublicKey:   "public_",
+				UrlEndpoint: "https://ik.imagekit.io/test/",
+			})
+			ik.Media.Config.API.Prefix = ts.URL + "/"
+
+			url, err := ik.DownloadURL(context.Background(), "123", time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			httpTest.Test("/files/123/details", "GET", nil)
+
+			u, err := neturl.Parse(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tc.signed {
+				if url != "https://ik.imagekit.io/test/public.jpg" {
+					t.Errorf("unexpected url: %s", url)
+				}
+				return
+			}
+
+			expires, _ := strconv.ParseInt(u.Query().Get("ik-t"), 10, 64)
+			if d := expires - time.Now().Add(time.Hour).Unix(); d < -5 || d > 5 {
+				t.Errorf("unexpected expiry: %d", expires)
+			}
+
+			if err = ik.ValidateSignedURL(url, tc.endpoint); err != nil {
+				t.Errorf("invalid signature of %s: %v", url, err)
+			}
+		})
+	}
+}
+
+func Test_ThumbnailURL(t *testing.T) {
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/dk1m7xkgi",
+	})
+
+	url, err := ik.ThumbnailURL("/new-york-cityscape-buildings_A4zxKJbrL.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://ik.imagekit.io/dk1m7xkgi/tr:n-ik_ml_thumbnail/new-york-cityscape-buildings_A4zxKJbrL.jpg"
+	if url != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, url)
+	}
+}
+
+func Test_NamedTransformationOverrides(t *testing.T) {
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/test/",
+	})
+
+	var cases = map[string]struct {
+		params   ikurl.UrlParam
+		expected string
+	}{
+		"named in step": {
+			params: ikurl.UrlParam{
+				Path:            "default-image.jpg",
+				Transformations: []map[string]any{{"named": "thumb", "width": 400}},
+			},
+			expected: "https://ik.imagekit.io/test/tr:n-thumb,w-400/default-image.jpg",
+		},
+		"named param": {
+			params: ikurl.UrlParam{
+				Path:                "default-image.jpg",
+				NamedTransformation: "thumb",
+				Transformations:     []map[string]any{{"width": 400}, {"rotation": 90}},
+			},
+			expected: "https://ik.imagekit.io/test/tr:n-thumb,w-400:rt-90/default-image.jpg",
+		},
+		"named param only": {
+			params: ikurl.UrlParam{
+				Path:                "default-image.jpg",
+				NamedTransformation: "thumb",
+			},
+			expected: "https://ik.imagekit.io/test/tr:n-thumb/default-image.jpg",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// parameters of a step are unordered, so render repeatedly to catch a misplaced n-
+			for i := 0; i < 20; i++ {
+				url, err := ik.Url(tc.params)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if url != tc.expected {
+					t.Fatalf("expected: %s\ngot: %s", tc.expected, url)
+				}
+			}
+		})
+	}
+}
+
+func Test_FolderThumbnails(t *testing.T) {
+	var query neturl.Values
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`[
+			{"fileId":"1","filePath":"/products/a.jpg","fileType":"image","isPrivateFile":false},
+			{"fileId":"2","filePath":"/products/b.jpg","fileType":"image","isPrivateFile":true}
+		]`))
+	}))
+	defer ts.Close()
+
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/test/",
+	})
+	ik.Media.Config.API.Prefix = ts.URL + "/"
+
+	thumbnails, err := ik.FolderThumbnails(context.Background(), "/products", 200, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query.Get("path") != "/products" || query.Get("fileType") != "image" || query.Get("type") != "file" {
+		t.Errorf("unexpected listing query: %v", query)
+	}
+
+	if len(thumbnails) != 2 || thumbnails[0].File.FileId != "1" || thumbnails[1].File.FileId != "2" {
+		t.Fatalf("unexpected thumbnails: %v", thumbnails)
+	}
+
+	if expected := "https://ik.imagekit.io/test/tr:w-200/products/a.jpg"; thumbnails[0].ThumbnailURL != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, thumbnails[0].ThumbnailURL)
+	}
+
+	if err = ik.ValidateSignedURL(thumbnails[1].ThumbnailURL); err != nil || !strings.Contains(thumbnails[1].ThumbnailURL, "tr:w-200/products/b.jpg?ik-s=") {
+		t.Errorf("expected signed thumbnail, got %s (%v)", thumbnails[1].ThumbnailURL, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err = ik.FolderThumbnails(ctx, "/products", 200, 200); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func Test_FileURL(t *testing.T) {
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/test/",
+	}, config.WithFileTypeTransformations(string(media.Image), map[string]any{"format": "auto"}, map[string]any{"quality": "auto"}))
+
+	var cases = map[string]struct {
+		file   media.File
+		params ikurl.UrlParam
+		url    string
+	}{
+		"image defaults": {
+			file: media.File{FilePath: "/photos/a.jpg", FileType: media.Image},
+			url:  "https://ik.imagekit.io/test/tr:f-auto:q-auto/photos/a.jpg",
+		},
+		"image defaults before transformations": {
+			file:   media.File{FilePath: "/photos/a.jpg", FileType: media.Image},
+			params: ikurl.UrlParam{Transformations: []map[string]any{{"width": 300}}},
+			url:    "https://ik.imagekit.io/test/tr:f-auto:q-auto:w-300/photos/a.jpg",
+		},
+		"non-image without defaults": {
+			file: media.File{FilePath: "/docs/manual.pdf", FileType: media.NonImage},
+			url:  "https://ik.imagekit.io/test/docs/manual.pdf",
+		},
+		"non-image with transformations": {
+			file:   media.File{FilePath: "/videos/intro.mp4", FileType: media.NonImage},
+			params: ikurl.UrlParam{Transformations: []map[string]any{{"width": 300}}},
+			url:    "https://ik.imagekit.io/test/tr:w-300/videos/intro.mp4",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			url, err := ik.FileURL(tc.file, tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if url != tc.url {
+				t.Errorf("expected: %s\ngot: %s", tc.url, url)
+			}
+		})
+	}
+
+	if _, err := ik.FileURL(media.File{}, ikurl.UrlParam{}); err == nil {
+		t.Error("expected error for file without path")
+	}
+
+	var private, public = true, false
+
+	url, err := ik.FileURL(media.File{FilePath: "/docs/private.pdf", IsPrivateFile: &private}, ikurl.UrlParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(url, "?ik-s=") {
+		t.Errorf("expected signed url of private file, got %s", url)
+	}
+	if err = ik.ValidateSignedURL(url); err != nil {
+		t.Error(err)
+	}
+
+	if _, err = ik.FileURL(media.File{FilePath: "/docs/private.pdf", IsPrivateFile: &private}, ikurl.UrlParam{Strict: true}); err != ErrUnsignedPrivateFile {
+		t.Errorf("expected ErrUnsignedPrivateFile, got %v", err)
+	}
+
+	if _, err = ik.FileURL(media.File{FilePath: "/docs/private.pdf", IsPrivateFile: &private}, ikurl.UrlParam{Strict: true, Signed: true}); err != nil {
+		t.Errorf("unexpected error for strict signed url: %v", err)
+	}
+
+	url, err = ik.FileURL(media.File{FilePath: "/docs/public.pdf", IsPrivateFile: &public}, ikurl.UrlParam{})
+	if err != nil || url != "https://ik.imagekit.io/test/docs/public.pdf" {
+		t.Errorf("expected plain url of public file, got %s (%v)", url, err)
+	}
+}
+
+func Test_AccountURLEndpoint(t *testing.T) {
+	var body = `[{"fileId":"123","name":"beauty.jpg","filePath":"/sample/beauty.jpg","url":"https://ik.imagekit.io/dk1m7xkgi/sample/beauty.jpg"}]`
+
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, body))
+	defer ts.Close()
+
+	ik := NewFromParams(NewParams{
+		PrivateKey: "private_",
+		PublicKey:  "public_",
+	})
+	ik.Media.Config.API.Prefix = ts.URL + "/"
+
+	endpoint, err := ik.AccountURLEndpoint(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if endpoint != "https://ik.imagekit.io/dk1m7xkgi/" {
+		t.Errorf("unexpected endpoint: %s", endpoint)
+	}
+
+	httpTest.Test("/files?limit=1&type=file", "GET", nil)
+
+	// cached endpoint is returned without another request
+	ts.Close()
+
+	if endpoint, err = ik.AccountURLEndpoint(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if endpoint != "https://ik.imagekit.io/dk1m7xkgi/" {
+		t.Errorf("unexpected cached endpoint: %s", endpoint)
+	}
+
+	url, err := ik.Url(ikurl.UrlParam{Path: "sample/beauty.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if url != "https://ik.imagekit.io/dk1m7xkgi/sample/beauty.jpg" {
+		t.Errorf("unexpected url: %s", url)
+	}
+
+	empty := httptest.NewServer(httpTest.Handler(200, `[]`))
+	defer empty.Close()
+
+	ik = NewFromParams(NewParams{PrivateKey: "private_", PublicKey: "public_"})
+	ik.Media.Config.API.Prefix = empty.URL + "/"
+
+	if _, err = ik.AccountURLEndpoint(context.Background()); err == nil {
+		t.Error("expected error for an empty media library")
+	}
+}
+
+func Test_AccountURLEndpointDerive(t *testing.T) {
+	var cases = map[string]struct {
+		body     string
+		endpoint string
+	}{
+		"query": {
+			`[{"filePath":"/a.jpg","url":"https://ik.imagekit.io/demo/a.jpg?updatedAt=1"}]`,
+			"https://ik.imagekit.io/demo/",
+		},
+		"encoded path": {
+			`[{"filePath":"/my file.jpg","url":"https://ik.imagekit.io/demo/my%20file.jpg"}]`,
+			"https://ik.imagekit.io/demo/",
+		},
+		"path mismatch": {
+			`[{"filePath":"/b.jpg","url":"https://ik.imagekit.io/demo/a.jpg"}]`,
+			"",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ts := httptest.NewServer(iktest.NewHttp(t).Handler(200, tc.body))
+			defer ts.Close()
+
+			ik := NewFromParams(NewParams{PrivateKey: "private_", PublicKey: "public_"})
+			ik.Media.Config.API.Prefix = ts.URL + "/"
+
+			endpoint, err := ik.AccountURLEndpoint(context.Background())
+			if tc.endpoint == "" {
+				if err == nil {
+					t.Errorf("expected error, got endpoint %s", endpoint)
+				}
+				return
+			}
+
+			if err != nil || endpoint != tc.endpoint {
+				t.Errorf("unexpected endpoint %s, %v", endpoint, err)
+			}
+
+			if ik.Config.Cloud.UrlEndpoint != "" {
+				t.Error("configuration should not be changed")
+			}
+		})
+	}
+}
+
+func Test_AccountURLEndpointConcurrentUrl(t *testing.T) {
+	ts := httptest.NewServer(iktest.NewHttp(t).Handler(200, `[{"filePath":"/a.jpg","url":"https://ik.imagekit.io/demo/a.jpg"}]`))
+	defer ts.Close()
+
+	ik := NewFromParams(NewParams{PrivateKey: "private_", PublicKey: "public_"})
+	ik.Media.Config.API.Prefix = ts.URL + "/"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			ik.AccountURLEndpoint(context.Background())
+		}()
+
+		go func() {
+			defer wg.Done()
+			ik.Url(ikurl.UrlParam{Path: "a.jpg"})
+		}()
+	}
+	wg.Wait()
+
+	if url, _ := ik.Url(ikurl.UrlParam{Path: "a.jpg"}); url != "https://ik.imagekit.io/demo/a.jpg" {
+		t.Errorf("unexpected url %s", url)
+	}
+}
+
+func Test_Reupload(t *testing.T) {
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/test/",
+	})
+
+	upload := &iktest.RecordingTransport{Body: `{"fileId":"123","url":"https://ik.imagekit.io/test/products/shoe.jpg"}`}
+	purge := &iktest.RecordingTransport{StatusCode: 201, Body: `{"requestId":"req_1"}`}
+	ik.Uploader.Client = upload
+	ik.Media.Client = purge
+
+	resp, err := ik.Reupload(context.Background(), "/products/shoe.jpg", strings.NewReader("data"),
+		WithUploadParam(uploader.UploadParam{Tags: "summer", UseUniqueFileName: api.Bool(true)}),
+		WithPurge(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Data.Url != "https://ik.imagekit.io/test/products/shoe.jpg" {
+		t.Errorf("unexpected url: %s", resp.Data.Url)
+	}
+
+	req, _ := upload.Last()
+	_, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	form, err := multipart.NewReader(bytes.NewReader(req.Body), params["boundary"]).ReadForm(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"fileName":          "shoe.jpg",
+		"folder":            "/products",
+		"useUniqueFileName": "false",
+		"overwriteFile":     "true",
+		"tags":              "summer",
+	}
+
+	for k, v := range expected {
+		if got := form.Value[k]; len(got) != 1 || got[0] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got)
+		}
+	}
+
+	if purgeReq, _ := purge.Last(); len(purge.Requests()) != 1 || string(purgeReq.Body) != `{"url":"https://ik.imagekit.io/test/products/shoe.jpg"}` {
+		t.Errorf("unexpected purge requests: %v", purge.Requests())
+	}
+
+	purge.Reset()
+
+	if _, err = ik.Reupload(context.Background(), "products/shoe.jpg", "https://example.com/shoe.jpg"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(purge.Requests()) != 0 {
+		t.Error("cache should not be purged without WithPurge")
+	}
+
+	if _, err = ik.Reupload(context.Background(), "/products/", "https://example.com/shoe.jpg"); err == nil {
+		t.Error("expected error for a path without file name")
+	}
+}
+
+func Test_DownloadOriginal(t *testing.T) {
+	for _, private := range []bool{false, true} {
+		t.Run(fmt.Sprintf("private=%v", private), func(t *testing.T) {
+			var fileReq *http.Request
+			var ts *httptest.Server
+
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/files/123/details":
+					fmt.Fprintf(w, `{"fileId":"123","url":"%s/files/photo.jpg","filePath":"/photo.jpg","isPrivateFile":%v}`, ts.URL, private)
+				case "/files/photo.jpg":
+					fileReq = r
+					w.Header().Set("Content-Type", "image/jpeg")
+					w.Write([]byte("original bytes"))
+				default:
+					w.WriteHeader(404)
+				}
+			}))
+			defer ts.Close()
+
+			ik := NewFromParams(NewParams{
+				PrivateKey:  "private_",
+				PublicKey:   "public_",
+				UrlEndpoint: ts.URL + "/test/",
+			})
+			ik.Media.Config.API.Prefix = ts.URL + "/v1/"
+
+			body, contentType, err := ik.DownloadOriginal(context.Background(), "123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer body.Close()
+
+			data, _ := io.ReadAll(body)
+			if string(data) != "original bytes" || contentType != "image/jpeg" {
+				t.Errorf("unexpected download: %q %s", data, contentType)
+			}
+
+			query := fileReq.URL.Query()
+			if query.Get("tr") != "orig-true" {
+				t.Errorf("expected orig-true transformation, got %q", query.Get("tr"))
+			}
+
+			if signed := query.Get("ik-s") != ""; signed != private {
+				t.Errorf("expected signed=%v, got url %s", private, fileReq.URL)
+			}
+
+			// the file is served from another endpoint than the configured one
+			if private {
+				if err = ik.ValidateSignedURL(ts.URL+fileReq.URL.String(), ts.URL+"/files/"); err != nil {
+					t.Errorf("invalid signature of %s: %v", fileReq.URL, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_TransformedMetadata(t *testing.T) {
+	httpTest := iktest.NewHttp(t)
+	ts := httptest.NewServer(httpTest.Handler(200, `{"height":150,"width":300,"size":8250,"format":"webp"}`))
+	defer ts.Close()
+
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: "https://ik.imagekit.io/test/",
+	})
+	ik.Metadata.Config.API.Prefix = ts.URL + "/"
+
+	resp, err := ik.TransformedMetadata(context.Background(), ikurl.UrlParam{
+		Path:            "default-image.jpg",
+		Transformations: []map[string]any{{"width": 300}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpTest.Test("/metadata?url="+neturl.QueryEscape("https://ik.imagekit.io/test/tr:w-300/default-image.jpg"), "GET", nil)
+
+	if resp.Data.Width != 300 || resp.Data.Height != 150 || resp.Data.Size != 8250 || resp.Data.Format != "webp" {
+		t.Errorf("unexpected metadata: %+v", resp.Data)
+	}
+
+	if _, err = ik.TransformedMetadata(context.Background(), ikurl.UrlParam{Path: "a.jpg", Src: "https://ik.imagekit.io/test/a.jpg"}); err == nil {
+		t.Error("expected error for invalid params")
+	}
+}
+
+func Test_DownloadTransformed(t *testing.T) {
+	var block = make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/test/tr:w-100/photo.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write([]byte("transformed bytes"))
+		case "/test/tr:w-200/photo.jpg":
+			w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+			<-block
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	ik := NewFromParams(NewParams{
+		PrivateKey:  "private_",
+		PublicKey:   "public_",
+		UrlEndpoint: ts.URL + "/test/",
+	})
+
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "nested", "photo.jpg")
+
+	err := ik.DownloadTransformed(context.Background(), ikurl.UrlParam{
+		Path:            "photo.jpg",
+		Transformations: []map[string]any{{"width": 100}},
+	}, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := os.ReadFile(dest); string(data) != "transformed bytes" {
+		t.Errorf("unexpected file content: %q", data)
+	}
+
+	canceled := filepath.Join(dir, "canceled.jpg")
+	cancelCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err = ik.DownloadTransformed(cancelCtx, ikurl.UrlParam{
+		Path:            "photo.jpg",
+		Transformations: []map[string]any{{"width": 200}},
+	}, canceled); err == nil {
+		t.Error("expected error for a cancelled download")
+	}
+
+	if err = ik.DownloadTransformed(context.Background(), ikurl.UrlParam{Path: "missing.jpg"}, canceled); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "nested" {
+		t.Errorf("partial files left behind: %v", entries)
+	}
+}
+
+func Test_ParseWebhookEvent(t *testing.T) {
+	var cases = map[string]struct {
+		body  string
+		check func(t *testing.T, event WebhookEvent)
+	}{
+		"video ready": {
+			`{"type":"video.transformation.ready","id":"e1","created_at":"2024-01-02T03:04:05.000Z",` +
+				`"request":{"url":"https://ik.imagekit.io/demo/a.mp4?tr=w-100","x_request_id":"r1","user_agent":"Mozilla"},` +
+				`"data":{"asset":{"url":"https://ik.imagekit.io/demo/a.mp4"},"transformation":{"type":"video-transformation",` +
+				`"options":{"format":"mp4"},"output":{"url":"https://ik.imagekit.io/demo/a.mp4?tr=w-100","video_metadata":{"duration":12.5,"width":100,"height":56,"bitrate":800}}}}}`,
+			func(t *testing.T, event WebhookEvent) {
+				e, ok := event.(*VideoTransformationEvent)
+				if !ok {
+					t.Fatalf("unexpected event %T", event)
+				}
+
+				if e.Id != "e1" || e.CreatedAt.Year() != 2024 || e.Request.XRequestId != "r1" {
+					t.Errorf("unexpected event fields %+v", e.WebhookEventBase)
+				}
+
+				if e.Data.Transformation.Output == nil || e.Data.Transformation.Output.VideoMetadata.Width != 100 {
+					t.Errorf("unexpected output %+v", e.Data.Transformation.Output)
+				}
+			},
+		},
+		"video error": {
+			`{"type":"video.transformation.error","id":"e2","created_at":"2024-01-02T03:04:05.000Z",` +
+				`"data":{"asset":{"url":"https://ik.imagekit.io/demo/a.mp4"},"transformation":{"type":"video-transformation","error":{"reason":"encoding_failed"}}}}`,
+			func(t *testing.T, event WebhookEvent) {
+				e := event.(*VideoTransformationEvent)
+				if e.Data.Transformation.Error == nil || e.Data.Transformation.Error.Reason != "encoding_failed" {
+					t.Errorf("unexpected error %+v", e.Data.Transformation.Error)
+				}
+			},
+		},
+		"post transform": {
+			`{"type":"upload.post-transform.success","id":"e3","created_at":"2024-01-02T03:04:05.000Z",` +
+				`"request":{"x_request_id":"r3","transformation":{"type":"thumbnail","value":"w-150"}},` +
+				`"data":{"fileId":"f1","name":"a.mp4","url":"https://ik.imagekit.io/demo/a.mp4"}}`,
+			func(t *testing.T, event WebhookEvent) {
+				e, ok := event.(*UploadTransformEvent)
+				if !ok {
+					t.Fatalf("unexpected event %T", event)
+				}
+
+				if e.Failed() || e.Data.FileId != "f1" {
+					t.Errorf("unexpected event %+v", e)
+				}
+			},
+		},
+		"pre transform error": {
+			`{"type":"upload.pre-transform.error","id":"e4","created_at":"2024-01-02T03:04:05.000Z",` +
+				`"request":{"x_request_id":"r4","transformation":"w-100"},` +
+				`"data":{"name":"a.jpg","path":"/a.jpg","transformation":{"error":{"reason":"invalid_transformation"}}}}`,
+			func(t *testing.T, event WebhookEvent) {
+				e := event.(*UploadTransformEvent)
+				if !e.Failed() || e.Data.Transformation == nil || e.Data.Transformation.Error.Reason != "invalid_transformation" {
+					t.Errorf("unexpected event %+v", e)
+				}
+			},
+		},
+		"unknown": {
+			`{"type":"file.created","id":"e5","created_at":"2024-01-02T03:04:05.000Z","data":{"fileId":"f1"}}`,
+			func(t *testing.T, event WebhookEvent) {
+				e, ok := event.(*UnknownWebhookEvent)
+				if !ok || e.EventType() != "file.created" || string(e.Data) != `{"fileId":"f1"}` {
+					t.Errorf("unexpected event %#v", event)
+				}
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			event, err := ParseWebhookEvent([]byte(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, event)
+		})
+	}
+
+	for _, invalid := range []string{`{"id":"e6"}`, `not json`} {
+		if _, err := ParseWebhookEvent([]byte(invalid)); err == nil {
+			t.Errorf("%s: expected error", invalid)
+		}
+	}
+}
+
+func Test_VerifyWebhookEvent(t *testing.T) {
+	var body = []byte(`{"type":"video.transformation.accepted","id":"e1","created_at":"2024-01-02T03:04:05.000Z"}`)
+	var secret = "whsec_test"
+
+	sign := func(ts time.Time, body []byte) string {
+		timestamp := strconv.FormatInt(ts.UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	event, err := VerifyWebhookEvent(body, sign(time.Now(), body), secret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := event.(*VideoTransformationEvent); !ok {
+		t.Errorf("unexpected event %T", event)
+	}
+
+	old := sign(time.Now().Add(-time.Hour), body)
+
+	if _, err = VerifyWebhookEvent(body, old, secret, time.Minute); err == nil {
+		t.Error("expected error for an old signature")
+	}
+
+	if _, err = VerifyWebhookEvent(body, old, secret, 0); err != nil {
+		t.Errorf("unexpected error without tolerance: %v", err)
+	}
+
+	for name, signature := range map[string]string{
+		"tampered body": sign(time.Now(), []byte(`{"type":"file.created"}`)),
+		"wrong mac":     strings.Replace(sign(time.Now(), body), "v1=", "v1=00", 1),
+		"malformed":     "v1=abc",
+		"empty":         "",
+	} {
+		if _, err = VerifyWebhookEvent(body, signature, secret, time.Minute); !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("%s: expected ErrInvalidWebhookSignature, got %v", name, err)
+		}
+	}
 }
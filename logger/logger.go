@@ -2,6 +2,7 @@
 package logger
 
 import (
+	"fmt"
 	"log"
 )
 
@@ -36,10 +37,14 @@ func (g *GoLog) Error(v ...interface{}) {
 	log.Println("ImageKit error", v)
 }
 
+// DefaultBodyLimit is the number of bytes of error response bodies logged by default.
+const DefaultBodyLimit = 1024
+
 // Logger is the logger struct.
 type Logger struct {
-	Writer LogWriter
-	level  Level
+	Writer    LogWriter
+	level     Level
+	bodyLimit int
 }
 
 // SetLevel sets the logger level.
@@ -47,6 +52,31 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
+// SetBodyLimit sets the number of bytes of error response bodies logged by ErrorResponse. Longer
+// bodies are truncated. Zero or less restores DefaultBodyLimit.
+func (l *Logger) SetBodyLimit(n int) {
+	l.bodyLimit = n
+}
+
+// ErrorResponse writes the status code and body of responses with a status code of 400 or more as
+// debug message, truncating the body to the body limit.
+func (l *Logger) ErrorResponse(statusCode int, body []byte) {
+	if l == nil || l.level != DEBUG || statusCode < 400 {
+		return
+	}
+
+	limit := l.bodyLimit
+	if limit <= 0 {
+		limit = DefaultBodyLimit
+	}
+
+	if len(body) > limit {
+		l.Writer.Debug("response", statusCode, fmt.Sprintf("%s... (%d bytes truncated)", body[:limit], len(body)-limit))
+		return
+	}
+	l.Writer.Debug("response", statusCode, string(body))
+}
+
 // Debug writes error messages.
 func (l *Logger) Error(v ...interface{}) {
 	if l.level >= ERROR {
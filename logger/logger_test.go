@@ -128,6 +128,39 @@ func TestLogger_LogLevel_None(t *testing.T) {
 	}
 }
 
+func TestLogger_ErrorResponse(t *testing.T) {
+	mock := MockLogger{
+		ErrorMessages: map[int][]string{},
+		DebugMessages: map[int][]string{},
+	}
+	log := Logger{Writer: mock}
+	log.SetLevel(DEBUG)
+	log.SetBodyLimit(10)
+
+	log.ErrorResponse(500, []byte("<html>internal server error</html>"))
+	log.ErrorResponse(404, []byte("not found"))
+	log.ErrorResponse(200, []byte("ok"))
+
+	if len(mock.DebugMessages) != 2 {
+		t.Fatalf("expected 2 debug messages, got %v", mock.DebugMessages)
+	}
+
+	if msg := mock.DebugMessages[0]; msg[1] != "500" || msg[2] != "<html>inte... (24 bytes truncated)" {
+		t.Errorf("unexpected truncated message: %v", msg)
+	}
+
+	if msg := mock.DebugMessages[1]; msg[1] != "404" || msg[2] != "not found" {
+		t.Errorf("unexpected message: %v", msg)
+	}
+
+	log.SetLevel(ERROR)
+	log.ErrorResponse(500, []byte("error"))
+
+	if len(mock.DebugMessages) != 2 {
+		t.Error("error responses should only be logged with level DEBUG")
+	}
+}
+
 func toStringsSlice(v ...interface{}) []string {
 	var res []string
 
@@ -0,0 +1,68 @@
+package imagekit
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/imagekit-developer/imagekit-go/api"
+	"github.com/imagekit-developer/imagekit-go/api/media"
+	"github.com/imagekit-developer/imagekit-go/api/uploader"
+)
+
+// ReuploadOption configures Reupload.
+type ReuploadOption func(*reuploadOptions)
+
+type reuploadOptions struct {
+	param uploader.UploadParam
+	purge bool
+}
+
+// WithPurge purges the CDN cache of the file url once it has been overwritten.
+func WithPurge() ReuploadOption {
+	return func(o *reuploadOptions) {
+		o.purge = true
+	}
+}
+
+// WithUploadParam sets further upload parameters such as tags or custom metadata. Its FileName,
+// Folder, UseUniqueFileName and OverwriteFile are ignored.
+func WithUploadParam(param uploader.UploadParam) ReuploadOption {
+	return func(o *reuploadOptions) {
+		o.param = param
+	}
+}
+
+// Reupload overwrites the file at filePath, e.g. /products/shoe.jpg, with file, which is anything
+// accepted by Uploader.Upload. It uploads with useUniqueFileName=false and overwriteFile=true, and
+// purges the cache of the resulting url when WithPurge is given.
+func (ik *ImageKit) Reupload(ctx context.Context, filePath string, file interface{}, opts ...ReuploadOption) (*uploader.UploadResponse, error) {
+	var o reuploadOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if filePath == "" || strings.HasSuffix(filePath, "/") {
+		return nil, errors.New("Reupload: filePath must include a file name")
+	}
+	name := path.Base(filePath)
+
+	param := o.param
+	param.FileName = name
+	param.Folder = path.Dir("/" + filePath)
+	param.UseUniqueFileName = api.Bool(false)
+	param.OverwriteFile = api.Bool(true)
+
+	resp, err := ik.Uploader.Upload(ctx, file, param)
+	if err != nil || !o.purge {
+		return resp, err
+	}
+
+	if _, err = ik.Media.PurgeCache(ctx, media.PurgeCacheParam{Url: resp.Data.Url}); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// RecordedRequest is a request captured by RecordingTransport.
+type RecordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// RecordingTransport captures every request sent through it so tests can assert on them without
+// running an httptest server. Requests are forwarded to Transport when set, otherwise answered with
+// StatusCode (200 when zero) and Body. It can be used as the Client of the API structs directly or
+// as the Transport of an http.Client.
+//
+//	rec := &test.RecordingTransport{Body: `{"successfullyUpdatedFileIds":["file_id"]}`}
+//	ik.Media.Client = rec
+type RecordingTransport struct {
+	Transport  http.RoundTripper
+	StatusCode int
+	Body       string
+
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// RoundTrip records req and returns the response of Transport or the canned response.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	t.mu.Lock()
+	t.requests = append(t.requests, RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	t.mu.Unlock()
+
+	if t.Transport != nil {
+		return t.Transport.RoundTrip(req)
+	}
+
+	statusCode := t.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(t.Body)),
+		Request:    req,
+	}, nil
+}
+
+// Do implements api.HttpClient.
+func (t *RecordingTransport) Do(req *http.Request) (*http.Response, error) {
+	return t.RoundTrip(req)
+}
+
+// Requests returns all recorded requests in the order they were sent.
+func (t *RecordingTransport) Requests() []RecordedRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]RecordedRequest(nil), t.requests...)
+}
+
+// Last returns the most recently recorded request.
+func (t *RecordingTransport) Last() (RecordedRequest, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.requests) == 0 {
+		return RecordedRequest{}, false
+	}
+	return t.requests[len(t.requests)-1], true
+}
+
+// Reset removes all recorded requests.
+func (t *RecordingTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.requests = nil
+}
@@ -0,0 +1,30 @@
+package test_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imagekit-developer/imagekit-go/api/media"
+	"github.com/imagekit-developer/imagekit-go/test"
+)
+
+func ExampleRecordingTransport() {
+	rec := &test.RecordingTransport{Body: `{"successfullyUpdatedFileIds":["file_id"]}`}
+
+	mediaApi, _ := media.NewFromConfiguration(test.Cfg)
+	mediaApi.Client = rec
+
+	resp, _ := mediaApi.AddTags(context.Background(), media.TagsParam{
+		FileIds: []string{"file_id"},
+		Tags:    []string{"summer"},
+	})
+
+	req, _ := rec.Last()
+	fmt.Println(req.Method, req.URL)
+	fmt.Println(string(req.Body))
+	fmt.Println(resp.Data.FileIds)
+	// Output:
+	// POST https://api.imagekit.io/v1/files/addTags
+	// {"fileIds":["file_id"],"tags":["summer"]}
+	// [file_id]
+}
@@ -12,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -115,7 +116,7 @@ func (h *Http) Test(url string, method string, body any) {
 
 type ErrorServer struct {
 	t          *testing.T
-	statusCode int
+	statusCode int32 // atomic, as requests abandoned by a canceled context may still be served
 	ts         *httptest.Server
 }
 
@@ -130,8 +131,9 @@ func (srv *ErrorServer) Url() string {
 }
 
 func (srv *ErrorServer) handler(w http.ResponseWriter, r *http.Request) {
-	log.Println(srv.statusCode)
-	w.WriteHeader(srv.statusCode)
+	code := int(atomic.LoadInt32(&srv.statusCode))
+	log.Println(code)
+	w.WriteHeader(code)
 	fmt.Fprintln(w, "{}")
 }
 
@@ -149,7 +151,7 @@ func (srv *ErrorServer) TestErrors(fn func() error) {
 	}
 
 	for code, expectedErr := range codesToErrors {
-		srv.statusCode = code
+		atomic.StoreInt32(&srv.statusCode, int32(code))
 		err := fn()
 		if !errors.Is(err, expectedErr) {
 			srv.t.Errorf("code %d: expected error %v, got: %v", code, expectedErr, err)
@@ -1,27 +1,47 @@
 package imagekit
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	neturl "net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/imagekit-developer/imagekit-go/api"
+	"github.com/imagekit-developer/imagekit-go/api/media"
+	"github.com/imagekit-developer/imagekit-go/api/metadata"
 	ikurl "github.com/imagekit-developer/imagekit-go/url"
 )
 
-// Url generates url from UrlParam
+// Url generates url from UrlParam. It fails for params rejected by UrlParam.Validate and for
+// signed urls when no private key is configured.
 func (ik *ImageKit) Url(params ikurl.UrlParam) (string, error) {
 	var resultUrl string
 	var url *neturl.URL
 	var err error
 	var endpoint = params.UrlEndpoint
 
+	if err = params.Validate(); err != nil {
+		return "", err
+	}
+
+	if params.Signed && ik.Config.Cloud.PrivateKey == "" {
+		return "", errors.New("signed url requires a private key")
+	}
+
 	if endpoint == "" {
-		endpoint = ik.Config.Cloud.UrlEndpoint
+		endpoint = ik.urlEndpoint()
 	}
 
 	endpoint = strings.TrimRight(endpoint, "/") + "/"
@@ -30,7 +50,43 @@ func (ik *ImageKit) Url(params ikurl.UrlParam) (string, error) {
 		params.QueryParameters = make(map[string]string)
 	}
 
+	var tr string
+
+	if params.Strict {
+		for _, step := range params.Transformations {
+			if err = validateStrict(step); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if params.NamedTransformation != "" {
+		var first = map[string]any{"named": params.NamedTransformation}
+		if len(params.Transformations) > 0 {
+			for k, v := range params.Transformations[0] {
+				if k != "named" {
+					first[k] = v
+				}
+			}
+			params.Transformations = append([]map[string]any{first}, params.Transformations[1:]...)
+		} else {
+			params.Transformations = []map[string]any{first}
+		}
+	}
+
+	if params.Transformations != nil {
+		if tr, err = joinTransformations(params.Transformations...); err != nil {
+			return "", err
+		}
+	}
+
 	if params.Src == "" {
+		if ik.Config.API.QueryTransformationFallback && strings.ContainsAny(params.Path, pathConflicts) {
+			params.TransformationPosition = ikurl.QUERY
+		}
+
+		params.Path = escapePath(strings.TrimLeft(params.Path, "/"))
+
 		if url, err = neturl.Parse(endpoint); err != nil {
 			return "", err
 		}
@@ -41,12 +97,12 @@ func (ik *ImageKit) Url(params ikurl.UrlParam) (string, error) {
 			}
 		} else {
 			if params.TransformationPosition == ikurl.QUERY {
-				params.QueryParameters["tr"] = joinTransformations(params.Transformations...)
+				params.QueryParameters["tr"] = tr
 				url, err = neturl.Parse(endpoint + params.Path)
 
 			} else {
 				url, err = neturl.Parse(url.String() +
-					"tr:" + joinTransformations(params.Transformations...) +
+					"tr:" + trEscaper.Replace(tr) +
 					"/" + strings.TrimLeft(params.Path, "/"))
 			}
 		}
@@ -56,12 +112,12 @@ func (ik *ImageKit) Url(params ikurl.UrlParam) (string, error) {
 		}
 
 		if params.Transformations != nil {
-			params.QueryParameters["tr"] = joinTransformations(params.Transformations...)
+			params.QueryParameters["tr"] = tr
 		}
 	}
 
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
 	query := url.Query()
@@ -69,49 +125,545 @@ func (ik *ImageKit) Url(params ikurl.UrlParam) (string, error) {
 	for k, v := range params.QueryParameters {
 		query.Set(k, v)
 	}
+
+	if params.Version != "" {
+		query.Set("ik-obj-version", params.Version)
+	}
+
+	if params.Attachment {
+		query.Set("ik-attachment", "true")
+	}
+
+	if !params.UpdatedAt.IsZero() {
+		query.Set("updatedAt", strconv.FormatInt(params.UpdatedAt.UnixMilli(), 10))
+	}
 	url.RawQuery = query.Encode()
 	resultUrl = url.String()
 
 	if params.Signed {
 		var now int64
+		var expires = noExpiry
+		var signParams string
 
-		if params.UnixTime == nil {
-			now = time.Now().Unix()
-		} else {
-			now = params.UnixTime()
+		if params.ExpireSeconds > 0 {
+			if params.UnixTime == nil {
+				now = time.Now().Unix()
+			} else {
+				now = params.UnixTime()
+			}
+
+			expires = strconv.FormatInt(now+int64(params.ExpireSeconds), 10)
+			signParams = "ik-t=" + expires + "&"
 		}
 
-		var expires = strconv.FormatInt(now+int64(params.ExpireSeconds), 10)
 		var path = strings.Replace(resultUrl, endpoint, "", 1)
 
-		path = path + expires
-		mac := hmac.New(sha1.New, []byte(ik.Config.Cloud.PrivateKey))
-		mac.Write([]byte(path))
-		signature := hex.EncodeToString(mac.Sum(nil))
+		signature := ik.urlSignature(path, expires)
+		signParams += "ik-s=" + signature
 
 		if strings.Index(resultUrl, "?") > -1 {
-			resultUrl = resultUrl + "&" + fmt.Sprintf("ik-t=%s&ik-s=%s", expires, signature)
+			resultUrl = resultUrl + "&" + signParams
 		} else {
-			resultUrl = resultUrl + "?" + fmt.Sprintf("ik-t=%s&ik-s=%s", expires, signature)
+			resultUrl = resultUrl + "?" + signParams
 		}
 	}
 
 	return resultUrl, nil
 }
 
-func joinTransformations(args ...map[string]any) string {
+// pathConflicts are the characters of a file path which trigger QueryTransformationFallback.
+const pathConflicts = ":,?#%"
+
+// pathEscaper escapes characters which would otherwise end the path of the url.
+var pathEscaper = strings.NewReplacer("?", "%3F", "#", "%23")
+
+// trEscaper escapes the transformation in the path of the url. Besides the characters escaped by
+// pathEscaper, a slash would end the tr segment and spaces, e.g. of a prompt, are not valid in a path.
+// In the query, the transformation is escaped along with the other query parameters instead.
+var trEscaper = strings.NewReplacer("?", "%3F", "#", "%23", "/", "%2F", " ", "%20")
+
+// escapePath escapes the query and fragment delimiters in a file path and percent signs which do not
+// start a valid escape sequence. Paths which are already escaped are left unchanged.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if _, err := neturl.PathUnescape(segment); err != nil {
+			segment = strings.ReplaceAll(segment, "%", "%25")
+		}
+		segments[i] = pathEscaper.Replace(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// ErrInvalidSignature is returned by ValidateSignedURL when the signature does not match the url.
+var ErrInvalidSignature = errors.New("invalid url signature")
+
+// ErrExpiredURL is returned by ValidateSignedURL when the signature has expired.
+var ErrExpiredURL = errors.New("signed url has expired")
+
+// ErrUnsignedPrivateFile is returned by FileURL for a Strict unsigned url of a private file, which
+// ImageKit would not serve.
+var ErrUnsignedPrivateFile = errors.New("url of private file must be signed")
+
+// noExpiry is the expiry timestamp signed into urls without ExpireSeconds. It is not added to the
+// url as ik-t.
+const noExpiry = "9999999999"
+
+// urlSignature signs path, the url without endpoint, with the private key for given expiry.
+func (ik *ImageKit) urlSignature(path string, expires string) string {
+	mac := hmac.New(sha1.New, []byte(ik.Config.Cloud.PrivateKey))
+	mac.Write([]byte(path + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateSignedURL recomputes the signature of a url generated by Url with the configured private
+// key. The url must start with one of endpoints, such as the UrlParam.UrlEndpoint it was generated
+// with, or with the configured url endpoint when none are given. It returns ErrInvalidSignature
+// when the signature does not match and ErrExpiredURL when it has expired.
+func (ik *ImageKit) ValidateSignedURL(signedUrl string, endpoints ...string) error {
+	u, err := neturl.Parse(signedUrl)
+	if err != nil {
+		return err
+	}
+
+	query := u.Query()
+	expires, signature := query.Get("ik-t"), query.Get("ik-s")
+
+	if signature == "" {
+		return errors.New("url is not signed")
+	}
+
+	suffix := "ik-s=" + signature
+	if expires != "" {
+		suffix = "ik-t=" + expires + "&" + suffix
+	} else {
+		expires = noExpiry
+	}
+	if !strings.HasSuffix(signedUrl, "?"+suffix) && !strings.HasSuffix(signedUrl, "&"+suffix) {
+		return ErrInvalidSignature
+	}
+
+	unsigned := signedUrl[:len(signedUrl)-len(suffix)-1]
+
+	if len(endpoints) == 0 {
+		endpoints = []string{ik.urlEndpoint()}
+	}
+
+	var path string
+	var found bool
+
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimRight(endpoint, "/") + "/"
+		if strings.HasPrefix(unsigned, endpoint) {
+			path, found = unsigned[len(endpoint):], true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("url does not start with endpoint %s", strings.Join(endpoints, " or "))
+	}
+
+	expected := ik.urlSignature(path, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	expiry, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if expiry < time.Now().Unix() {
+		return ErrExpiredURL
+	}
+
+	return nil
+}
+
+// ShareURL generates a signed url for params which expires after ttl. It is meant for temporarily sharing private files.
+func (ik *ImageKit) ShareURL(params ikurl.UrlParam, ttl time.Duration) (string, error) {
+	if ik.Config.Cloud.PrivateKey == "" {
+		return "", errors.New("ShareURL: private key is not configured")
+	}
+
+	if ttl < time.Second {
+		return "", errors.New("ShareURL: ttl must be at least one second")
+	}
+
+	params.Signed = true
+	params.ExpireSeconds = int64(ttl / time.Second)
+
+	return ik.Url(params)
+}
+
+// FileURL generates the url of file with params, using its FilePath as Path. The default
+// transformations configured for its FileType with config.WithFileTypeTransformations are applied
+// before params.Transformations. Urls of private files are signed, or rejected with
+// ErrUnsignedPrivateFile when params is Strict and not Signed.
+func (ik *ImageKit) FileURL(file media.File, params ikurl.UrlParam) (string, error) {
+	if file.FilePath == "" {
+		return "", errors.New("FileURL: file has no path")
+	}
+
+	if file.IsPrivateFile != nil && *file.IsPrivateFile && !params.Signed {
+		if params.Strict {
+			return "", ErrUnsignedPrivateFile
+		}
+		params.Signed = true
+	}
+
+	params.Path = file.FilePath
+	params.Src = ""
+
+	if defaults := ik.Config.API.FileTypeTransformations[string(file.FileType)]; len(defaults) > 0 {
+		params.Transformations = append(append([]map[string]any{}, defaults...), params.Transformations...)
+	}
+
+	return ik.Url(params)
+}
+
+// FolderThumbnail is an image of a folder with the url of its thumbnail, see FolderThumbnails.
+type FolderThumbnail struct {
+	File         media.File
+	ThumbnailURL string
+}
+
+// FolderThumbnails lists the images in folderPath and its subfolders, oldest first, with the url of
+// a width x height thumbnail of each, e.g. for a gallery. A zero width or height keeps the aspect
+// ratio, both zero give urls of the untransformed images. Thumbnails of private images are signed
+// without expiry.
+func (ik *ImageKit) FolderThumbnails(ctx context.Context, folderPath string, width, height int) ([]FolderThumbnail, error) {
+	var resize = map[string]any{}
+	var tr []map[string]any
+
+	if width > 0 {
+		resize["width"] = width
+	}
+	if height > 0 {
+		resize["height"] = height
+	}
+	if len(resize) > 0 {
+		tr = []map[string]any{resize}
+	}
+
+	it := ik.Media.FilesIterator(ctx, media.FilesParam{
+		Type:     media.ListFile,
+		Sort:     media.AscCreated,
+		Path:     folderPath,
+		FileType: media.Image,
+	})
+	defer it.Close()
+
+	var thumbnails []FolderThumbnail
+
+	for it.Next() {
+		file := it.File()
+
+		url, err := ik.FileURL(file, ikurl.UrlParam{Transformations: tr})
+		if err != nil {
+			return nil, err
+		}
+
+		thumbnails = append(thumbnails, FolderThumbnail{file, url})
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return thumbnails, nil
+}
+
+// ThumbnailURL returns the url of the ML generated thumbnail of the file at path, see ikurl.MLThumbnail.
+func (ik *ImageKit) ThumbnailURL(path string) (string, error) {
+	return ik.Url(ikurl.UrlParam{
+		Path:            path,
+		Transformations: []map[string]any{{"named": ikurl.MLThumbnail}},
+	})
+}
+
+// LQIPOption configures the placeholder generated by LQIPURL.
+type LQIPOption func(*lqipOptions)
+
+type lqipOptions struct {
+	width, blur, quality int
+}
+
+// WithLQIPWidth sets the width of the placeholder in pixels, 20 by default.
+func WithLQIPWidth(width int) LQIPOption {
+	return func(o *lqipOptions) {
+		o.width = width
+	}
+}
+
+// WithLQIPBlur sets the blur radius of the placeholder, 10 by default.
+func WithLQIPBlur(blur int) LQIPOption {
+	return func(o *lqipOptions) {
+		o.blur = blur
+	}
+}
+
+// WithLQIPQuality sets the quality of the placeholder, 20 by default.
+func WithLQIPQuality(quality int) LQIPOption {
+	return func(o *lqipOptions) {
+		o.quality = quality
+	}
+}
+
+// LQIPURL returns the url of a tiny, blurred, low quality placeholder of the image described by
+// params, rendered as a chained w-20,bl-10,q-20 step after its transformations.
+func (ik *ImageKit) LQIPURL(params ikurl.UrlParam, opts ...LQIPOption) (string, error) {
+	o := lqipOptions{width: 20, blur: 10, quality: 20}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.width <= 0 || o.blur < 0 || o.quality <= 0 || o.quality > 100 {
+		return "", errors.New("LQIPURL: invalid placeholder parameters")
+	}
+
+	params.Transformations = append(append([]map[string]any{}, params.Transformations...), map[string]any{
+		"width":   o.width,
+		"blur":    o.blur,
+		"quality": o.quality,
+	})
+
+	return ik.Url(params)
+}
+
+// DownloadURL returns the url of the file with given fileId. Private files get a url signed for
+// ttl against the endpoint of the file url, which may differ from the configured one, public files
+// their plain url.
+func (ik *ImageKit) DownloadURL(ctx context.Context, fileId string, ttl time.Duration) (string, error) {
+	resp, err := ik.Media.FileById(ctx, fileId)
+	if err != nil {
+		return "", err
+	}
+
+	file := resp.Data
+
+	if file.IsPrivateFile == nil || !*file.IsPrivateFile {
+		return file.Url, nil
+	}
+
+	endpoint, err := fileEndpoint(file)
+	if err != nil {
+		return "", err
+	}
+
+	return ik.ShareURL(ikurl.UrlParam{Src: file.Url, UrlEndpoint: endpoint}, ttl)
+}
+
+// originalDownloadTTL is the expiry of signed urls used by DownloadOriginal.
+const originalDownloadTTL = 5 * time.Minute
+
+// DownloadOriginal streams the original, untransformed file with given fileId, e.g. for backups.
+// The url of private files is signed against the endpoint of the file url. It returns the body,
+// which must be closed by the caller, and its content type.
+func (ik *ImageKit) DownloadOriginal(ctx context.Context, fileId string) (io.ReadCloser, string, error) {
+	resp, err := ik.Media.FileById(ctx, fileId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	params := ikurl.UrlParam{
+		Src:             resp.Data.Url,
+		Transformations: []map[string]any{{"original": "true"}},
+	}
+
+	var fileUrl string
+
+	if resp.Data.IsPrivateFile != nil && *resp.Data.IsPrivateFile {
+		if params.UrlEndpoint, err = fileEndpoint(resp.Data); err != nil {
+			return nil, "", err
+		}
+		fileUrl, err = ik.ShareURL(params, originalDownloadTTL)
+	} else {
+		fileUrl, err = ik.Url(params)
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return ik.fetch(ctx, fileUrl)
+}
+
+// TransformedMetadata returns the metadata, such as dimensions, size and format, of the image
+// generated from params rather than of the original file. The url built from params is passed to
+// the metadata api, which fetches and analyzes the transformed image like any ImageKit url.
+func (ik *ImageKit) TransformedMetadata(ctx context.Context, params ikurl.UrlParam) (*metadata.MetadataResponse, error) {
+	fileUrl, err := ik.Url(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return ik.Metadata.FromUrl(ctx, fileUrl)
+}
+
+// DownloadTransformed streams the image generated from params to destPath. Missing parent
+// directories are created. The file is written to a temporary file first, so destPath is left
+// untouched when the download fails or ctx is cancelled.
+func (ik *ImageKit) DownloadTransformed(ctx context.Context, params ikurl.UrlParam, destPath string) error {
+	fileUrl, err := ik.Url(params)
+	if err != nil {
+		return err
+	}
+
+	body, _, err := ik.fetch(ctx, fileUrl)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err = os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), "."+filepath.Base(destPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), destPath)
+}
+
+// fetch downloads fileUrl, returning the body, which must be closed by the caller, and its content
+// type. Error responses are returned as *api.ApiError.
+func (ik *ImageKit) fetch(ctx context.Context, fileUrl string) (io.ReadCloser, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var userAgent = ik.Config.API.UserAgent
+	if userAgent == "" {
+		userAgent = api.DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	var client = ik.Media.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		errResp := &api.Response{ResponseMetaData: api.ResponseMetaData{
+			Header:     resp.Header,
+			StatusCode: resp.StatusCode,
+			Body:       body,
+		}}
+		return nil, "", errResp.ParseError()
+	}
+
+	return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
+// encodingFormats are the output formats supporting the progressive and interlace encodings.
+// auto and orig are accepted as they may result in a supported format. Formats are matched
+// case-sensitively, like Format.Valid.
+var encodingFormats = map[string]map[ikurl.Format]bool{
+	"progressive": {ikurl.FormatJPG: true, ikurl.FormatJPEG: true, ikurl.FormatAuto: true, ikurl.FormatOrig: true},
+	"interlace":   {ikurl.FormatPNG: true, ikurl.FormatGIF: true, ikurl.FormatAuto: true, ikurl.FormatOrig: true},
+}
+
+// validateStrict rejects a transformation step with an unknown format or combining incompatible
+// parameters.
+func validateStrict(tr map[string]any) error {
+	f, hasFormat := tr["format"]
+	format := ikurl.Format(fmt.Sprint(f))
+
+	if hasFormat && !format.Valid() {
+		return fmt.Errorf("unsupported format %v", f)
+	}
+
+	progressive, interlace := isTrue(tr["progressive"]), isTrue(tr["interlace"])
+
+	if progressive && interlace {
+		return errors.New("progressive and interlace can not be combined")
+	}
+
+	if progressive && hasFormat && !encodingFormats["progressive"][format] {
+		return fmt.Errorf("progressive is only supported for JPEG output, not format %v", f)
+	}
+
+	if interlace && hasFormat && !encodingFormats["interlace"][format] {
+		return fmt.Errorf("interlace is only supported for PNG and GIF output, not format %v", f)
+	}
+
+	return nil
+}
+
+// isTrue reports whether a transformation value renders as true.
+func isTrue(v any) bool {
+	return v != nil && fmt.Sprint(v) == "true"
+}
+
+func joinTransformations(args ...map[string]any) (string, error) {
 	var parts []string
 
 	for _, v := range args {
-		parts = append(parts, transform(v))
+		part, err := transform(v, false)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
 	}
-	return strings.Join(parts, ":")
+	return strings.Join(parts, ":"), nil
 }
 
-func transform(tr map[string]any) string {
+// transform serializes a single transformation. inLayer allows parameters which are only
+// valid within a layer. A named transformation is emitted first so the other parameters of the
+// step override it, e.g. n-thumb,w-400.
+func transform(tr map[string]any, inLayer bool) (string, error) {
 	var parts []string
+	var named string
 
 	for k, v := range tr {
+		if layer, ok := v.(ikurl.Layer); ok {
+			if inLayer {
+				return "", errors.New("nested layers are not supported")
+			}
+
+			value, err := transformLayer(layer)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, value)
+			continue
+		}
+
+		if fill, ok := v.(ikurl.GenerativeFill); ok {
+			value, err := transformGenerativeFill(fill)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, value)
+			continue
+		}
+
 		value := fmt.Sprintf("%v", v)
 
 		if k == "raw" {
@@ -125,8 +677,14 @@ func transform(tr map[string]any) string {
 			continue
 		}
 
+		if !inLayer && ikurl.LayerOnly[k] {
+			return "", fmt.Errorf("%s can only be used within a layer", k)
+		}
+
 		if v == "-" {
 			parts = append(parts, prefix)
+		} else if k == "named" {
+			named = prefix + "-" + value
 		} else {
 			if prefix == "di" || prefix == "oi" {
 				value = strings.ReplaceAll(strings.Trim(value, "/"), "/", "@@")
@@ -135,5 +693,62 @@ func transform(tr map[string]any) string {
 		}
 	}
 
-	return strings.Join(parts, ",")
+	if named != "" {
+		parts = append([]string{named}, parts...)
+	}
+
+	return strings.Join(parts, ","), nil
+}
+
+// plainTextPattern matches prompts and layer texts which can be used as is in a url once spaces are
+// escaped. Others are sent base64 encoded.
+var plainTextPattern = regexp.MustCompile(`^[a-zA-Z0-9 ]+$`)
+
+// transformGenerativeFill serializes fill as w-<width>,h-<height>,cm-pad_resize,bg-genfill with
+// the prompt appended as prompt-<text> or, when it contains other than alphanumerics and spaces,
+// as base64 encoded prompte-<text>.
+func transformGenerativeFill(fill ikurl.GenerativeFill) (string, error) {
+	if fill.Width <= 0 || fill.Height <= 0 {
+		return "", errors.New("generative fill requires a positive width and height")
+	}
+
+	value := "bg-genfill"
+
+	if fill.Prompt != "" {
+		if plainTextPattern.MatchString(fill.Prompt) {
+			value += "-prompt-" + fill.Prompt
+		} else {
+			value += "-prompte-" + base64.StdEncoding.EncodeToString([]byte(fill.Prompt))
+		}
+	}
+
+	return fmt.Sprintf("w-%d,h-%d,cm-pad_resize,%s", fill.Width, fill.Height, value), nil
+}
+
+// transformLayer serializes layer as l-<type>,i-<input>,<transformations>,l-end. Texts with
+// characters other than letters, digits and spaces, such as a comma starting the next parameter,
+// are sent base64 encoded as ie-<input>.
+func transformLayer(layer ikurl.Layer) (string, error) {
+	if layer.Type == "" || layer.Input == "" {
+		return "", errors.New("layer type and input are required")
+	}
+
+	input := "i-" + layer.Input
+	if layer.Type == ikurl.ImageLayer || layer.Type == ikurl.VideoLayer {
+		input = "i-" + strings.ReplaceAll(strings.Trim(layer.Input, "/"), "/", "@@")
+	} else if layer.Type == ikurl.TextLayer && !plainTextPattern.MatchString(layer.Input) {
+		input = "ie-" + base64.StdEncoding.EncodeToString([]byte(layer.Input))
+	}
+
+	parts := []string{"l-" + string(layer.Type), input}
+
+	if len(layer.Transformations) > 0 {
+		nested, err := transform(layer.Transformations, true)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, nested)
+	}
+
+	return strings.Join(append(parts, "l-end"), ","), nil
 }
@@ -0,0 +1,144 @@
+package url
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ResultDimensions returns the size of an image of srcW x srcH after applying the resize and crop
+// parameters of the transformation step t, e.g. to reserve space in a layout before the image is
+// loaded. It follows ImageKit's rules:
+//   - maintain_ratio (the default) returns the requested size when both width and height are set,
+//     otherwise the missing dimension is derived from the aspect ratio
+//   - force returns the requested size, a missing dimension keeps the source value
+//   - at_max fits the image within the requested size without enlarging it
+//   - at_least scales the image so both dimensions are at least the requested size
+//   - cropMode pad_resize, pad_extract and extract return the requested size
+//
+// Width and height values below 1 are relative to the source, aspectRatio is used when only one of
+// them is set. Other parameters are ignored.
+func ResultDimensions(srcW, srcH int, t map[string]any) (int, int) {
+	if srcW <= 0 || srcH <= 0 {
+		return 0, 0
+	}
+
+	sw, sh := float64(srcW), float64(srcH)
+	w := dimension(t["width"], sw)
+	h := dimension(t["height"], sh)
+
+	if ratio := aspectRatio(t["aspectRatio"]); ratio > 0 {
+		if w > 0 && h == 0 {
+			h = w / ratio
+		} else if h > 0 && w == 0 {
+			w = h * ratio
+		}
+	}
+
+	if w == 0 && h == 0 {
+		return srcW, srcH
+	}
+
+	crop, _ := t["crop"].(string)
+	cropMode, _ := t["cropMode"].(string)
+
+	switch {
+	case cropMode == "extract" || cropMode == "pad_extract" || cropMode == "pad_resize" || crop == "force":
+		if w == 0 {
+			w = sw
+			if cropMode == "pad_resize" {
+				w = h * sw / sh
+			}
+		}
+		if h == 0 {
+			h = sh
+			if cropMode == "pad_resize" {
+				h = w * sh / sw
+			}
+		}
+		if cropMode == "extract" {
+			w, h = math.Min(w, sw), math.Min(h, sh)
+		}
+		return round(w), round(h)
+
+	case crop == "at_max":
+		scale := 1.0
+		if w > 0 {
+			scale = math.Min(scale, w/sw)
+		}
+		if h > 0 {
+			scale = math.Min(scale, h/sh)
+		}
+		return round(sw * scale), round(sh * scale)
+
+	case crop == "at_least":
+		scale := math.Max(w/sw, h/sh)
+		return round(sw * scale), round(sh * scale)
+	}
+
+	// maintain_ratio
+	switch {
+	case w == 0:
+		w = h * sw / sh
+	case h == 0:
+		h = w * sh / sw
+	}
+	return round(w), round(h)
+}
+
+// dimension converts a width or height transformation value to pixels, values below 1 are
+// relative to src. It returns 0 when v is not set or invalid.
+func dimension(v any, src float64) float64 {
+	var f float64
+
+	switch n := v.(type) {
+	case int:
+		f = float64(n)
+	case int64:
+		f = float64(n)
+	case float64:
+		f = n
+	case string:
+		var err error
+		if f, err = strconv.ParseFloat(n, 64); err != nil {
+			return 0
+		}
+	default:
+		return 0
+	}
+
+	if f <= 0 {
+		return 0
+	}
+	if f < 1 {
+		return f * src
+	}
+	return f
+}
+
+// aspectRatio parses an aspectRatio value formatted as width-height, e.g. 4-3.
+func aspectRatio(v any) float64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+
+	w, h, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0
+	}
+
+	fw, err := strconv.ParseFloat(w, 64)
+	if err != nil {
+		return 0
+	}
+	fh, err := strconv.ParseFloat(h, 64)
+	if err != nil || fh == 0 {
+		return 0
+	}
+	return fw / fh
+}
+
+func round(f float64) int {
+	return int(math.Round(f))
+}
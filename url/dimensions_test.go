@@ -0,0 +1,41 @@
+package url
+
+import "testing"
+
+func TestResultDimensions(t *testing.T) {
+	var cases = map[string]struct {
+		tr     map[string]any
+		width  int
+		height int
+	}{
+		"no resize":               {map[string]any{"blur": 10}, 1000, 500},
+		"maintain ratio width":    {map[string]any{"width": 400}, 400, 200},
+		"maintain ratio height":   {map[string]any{"height": "100"}, 200, 100},
+		"maintain ratio both":     {map[string]any{"width": 300, "height": 300}, 300, 300},
+		"maintain ratio explicit": {map[string]any{"width": 300, "height": 300, "crop": "maintain_ratio"}, 300, 300},
+		"relative width":          {map[string]any{"width": 0.5}, 500, 250},
+		"aspect ratio":            {map[string]any{"width": 400, "aspectRatio": "4-3"}, 400, 300},
+		"force":                   {map[string]any{"width": 300, "height": 300, "crop": "force"}, 300, 300},
+		"force width only":        {map[string]any{"width": 300, "crop": "force"}, 300, 500},
+		"at max":                  {map[string]any{"width": 400, "height": 400, "crop": "at_max"}, 400, 200},
+		"at max no enlarge":       {map[string]any{"width": 2000, "height": 2000, "crop": "at_max"}, 1000, 500},
+		"at max height only":      {map[string]any{"height": 100, "crop": "at_max"}, 200, 100},
+		"at least":                {map[string]any{"width": 400, "height": 400, "crop": "at_least"}, 800, 400},
+		"at least enlarge":        {map[string]any{"width": 3000, "height": 100, "crop": "at_least"}, 3000, 1500},
+		"pad resize":              {map[string]any{"width": 300, "height": 300, "cropMode": "pad_resize"}, 300, 300},
+		"extract within source":   {map[string]any{"width": 2000, "height": 300, "cropMode": "extract"}, 1000, 300},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w, h := ResultDimensions(1000, 500, tc.tr)
+			if w != tc.width || h != tc.height {
+				t.Errorf("expected %dx%d, got %dx%d", tc.width, tc.height, w, h)
+			}
+		})
+	}
+
+	if w, h := ResultDimensions(0, 500, map[string]any{"width": 100}); w != 0 || h != 0 {
+		t.Errorf("expected 0x0 for invalid source, got %dx%d", w, h)
+	}
+}
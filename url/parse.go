@@ -0,0 +1,113 @@
+package url
+
+import (
+	neturl "net/url"
+	"sort"
+	"strings"
+)
+
+// ParseTransformation extracts the transformations applied to a generated url. Transformations
+// are read from the path (tr:...) or from the tr query parameter and returned in the same
+// representation as UrlParam.Transformations, one map per chained transformation. Parameters
+// which have no name in TransformationCode are returned under the "raw" key.
+func ParseTransformation(url string) ([]map[string]any, error) {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var tr string
+
+	for _, part := range strings.Split(u.Path, "/") {
+		if strings.HasPrefix(part, "tr:") {
+			tr = strings.TrimPrefix(part, "tr:")
+			break
+		}
+	}
+
+	if tr == "" {
+		tr = u.Query().Get("tr")
+	}
+
+	var result []map[string]any
+
+	if tr == "" {
+		return result, nil
+	}
+
+	for _, chain := range strings.Split(tr, ":") {
+		var params = map[string]any{}
+		var raw []string
+		var inLayer bool
+
+		for _, token := range strings.Split(chain, ",") {
+			if token == "" {
+				continue
+			}
+
+			// layers are kept as raw tokens since their order matters
+			if strings.HasPrefix(token, "l-") || inLayer {
+				inLayer = token != "l-end"
+				raw = append(raw, token)
+				continue
+			}
+
+			name, value, ok := parseToken(token)
+			if !ok {
+				raw = append(raw, token)
+				continue
+			}
+			params[name] = value
+		}
+
+		if raw != nil {
+			params["raw"] = strings.Join(raw, ",")
+		}
+		result = append(result, params)
+	}
+
+	return result, nil
+}
+
+// parseToken splits a transformation token such as w-100 into its parameter name and value
+// using the longest matching code.
+func parseToken(token string) (string, string, bool) {
+	var code string
+
+	for c := range transformationNames {
+		if (token == c || strings.HasPrefix(token, c+"-")) && len(c) > len(code) {
+			code = c
+		}
+	}
+
+	if code == "" {
+		return "", "", false
+	}
+
+	value := strings.TrimPrefix(strings.TrimPrefix(token, code), "-")
+	if value == "" {
+		value = "-"
+	}
+
+	if code == "di" || code == "oi" {
+		value = strings.ReplaceAll(value, "@@", "/")
+	}
+
+	return transformationNames[code], value, true
+}
+
+// transformationNames maps url prefix codes back to parameter names. When several names share
+// a code, the alphabetically first one is used.
+var transformationNames = func() map[string]string {
+	var names []string
+	for name := range TransformationCode {
+		names = append(names, name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	var codes = map[string]string{}
+	for _, name := range names {
+		codes[TransformationCode[name]] = name
+	}
+	return codes
+}()
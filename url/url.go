@@ -1,5 +1,7 @@
 package url
 
+import "time"
+
 type trpos string
 
 const (
@@ -7,12 +9,91 @@ const (
 	QUERY trpos = "query"
 )
 
+// MLThumbnail is the name of ImageKit's built-in named transformation which generates a thumbnail
+// using machine learning to pick the most relevant area of the image. It is the transformation used
+// in the thumbnail url of files returned by the api.
+const MLThumbnail = "ik_ml_thumbnail"
+
+// LayerType is the type of a Layer.
+type LayerType string
+
+const (
+	ImageLayer LayerType = "image"
+	TextLayer  LayerType = "text"
+	VideoLayer LayerType = "video"
+)
+
+// Layer is an overlay with its own transformations, e.g. a blurred, semi-transparent image. It is
+// used as a value in UrlParam.Transformations and rendered as l-<type>,i-<input>,...,l-end:
+//
+//	{"layer": Layer{Type: ImageLayer, Input: "logo.png", Transformations: map[string]any{"blur": 10, "opacity": 50}}}
+type Layer struct {
+	Type            LayerType
+	Input           string // file path for image and video layers, text for text layers
+	Transformations map[string]any
+}
+
+// Format is the output format set with the format transformation, e.g. {"format": FormatWebP}
+// rendered as f-webp.
+type Format string
+
+const (
+	FormatAuto Format = "auto"
+	FormatJPG  Format = "jpg"
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatWebP Format = "webp"
+	FormatAVIF Format = "avif"
+	FormatGIF  Format = "gif"
+	FormatSVG  Format = "svg"
+	FormatMP4  Format = "mp4"
+	FormatWebM Format = "webm"
+	FormatOrig Format = "orig"
+)
+
+// Valid reports whether f is an output format supported by ImageKit.
+func (f Format) Valid() bool {
+	switch f {
+	case FormatAuto, FormatJPG, FormatJPEG, FormatPNG, FormatWebP, FormatAVIF, FormatGIF,
+		FormatSVG, FormatMP4, FormatWebM, FormatOrig:
+		return true
+	}
+	return false
+}
+
+// GenerativeFill extends an image to Width x Height by padding it with AI generated content,
+// optionally guided by Prompt. It is used as a value in UrlParam.Transformations and rendered as
+// w-<width>,h-<height>,cm-pad_resize,bg-genfill[-prompt-<prompt>]:
+//
+//	{"generativeFill": GenerativeFill{Width: 1200, Height: 600, Prompt: "snowy mountains"}}
+type GenerativeFill struct {
+	Width  int
+	Height int
+	Prompt string
+}
+
 type UrlParam struct {
 	Path                string
 	Src                 string
 	UrlEndpoint         string
 	Transformations     []map[string]any
-	NamedTransformation string // n-trname
+	NamedTransformation string // n-trname, prepended to the first step so its parameters override it
+	Version             string // version id of the file, sent as ik-obj-version and covered by the signature
+
+	// Attachment adds ik-attachment=true, making ImageKit serve the file with a Content-Disposition:
+	// attachment header so browsers download it instead of displaying it.
+	Attachment bool
+
+	// UpdatedAt adds updatedAt=<unix milliseconds>, which ImageKit ignores. Caches keyed by the full
+	// url, such as browsers, treat a url with a new UpdatedAt as a new resource, so setting it to the
+	// update time of the file makes them fetch the current version.
+	UpdatedAt time.Time
+
+	// Strict rejects transformation steps with an unknown format or combining incompatible
+	// parameters, such as progressive with a non-JPEG format or interlace with a non-PNG/GIF
+	// format, instead of leaving ImageKit to ignore them. FileURL also rejects unsigned urls of
+	// private files instead of signing them.
+	Strict bool
 
 	Signed                 bool
 	ExpireSeconds          int64
@@ -74,6 +155,7 @@ var TransformationCode = map[string]string{
 	"overlayTextInnerAlignment": "otia",
 	"overlayRadius":             "or",
 	"progressive":               "pr",
+	"interlace":                 "il",
 	"lossless":                  "lo",
 	"trim":                      "t",
 	"metadata":                  "md",
@@ -84,5 +166,18 @@ var TransformationCode = map[string]string{
 	"effectUSM":                 "e-usm",
 	"effectContrast":            "e-contrast",
 	"effectGray":                "e-grayscale",
+	"effectGenVariation":        "e-genvar",
 	"original":                  "orig",
+	"opacity":                   "o",
+	"layerX":                    "lx",
+	"layerY":                    "ly",
+	"layerFocus":                "lfo",
+}
+
+// LayerOnly lists transformation parameters which are only valid within a Layer.
+var LayerOnly = map[string]bool{
+	"opacity":    true,
+	"layerX":     true,
+	"layerY":     true,
+	"layerFocus": true,
 }
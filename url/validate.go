@@ -0,0 +1,50 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate reports settings of p which conflict with each other: both Path and Src, an unknown
+// TransformationPosition, or QueryParameters which the generated url sets itself, such as tr when
+// the transformations are put in the query. Url returns the same errors, Validate allows to check
+// params without building the url, e.g. in tests.
+func (p UrlParam) Validate() error {
+	if p.Path != "" && p.Src != "" {
+		return errors.New("path and src can not be used together")
+	}
+
+	switch p.TransformationPosition {
+	case "", PATH, QUERY:
+	default:
+		return fmt.Errorf("unknown transformation position %q", p.TransformationPosition)
+	}
+
+	hasTransformations := p.Transformations != nil || p.NamedTransformation != ""
+
+	if _, ok := p.QueryParameters["tr"]; ok && hasTransformations && (p.Src != "" || p.TransformationPosition == QUERY) {
+		return errors.New("query parameter tr conflicts with the transformations")
+	}
+
+	if _, ok := p.QueryParameters["ik-obj-version"]; ok && p.Version != "" {
+		return errors.New("query parameter ik-obj-version conflicts with the version")
+	}
+
+	if _, ok := p.QueryParameters["ik-attachment"]; ok && p.Attachment {
+		return errors.New("query parameter ik-attachment conflicts with attachment")
+	}
+
+	if _, ok := p.QueryParameters["updatedAt"]; ok && !p.UpdatedAt.IsZero() {
+		return errors.New("query parameter updatedAt conflicts with the update time")
+	}
+
+	if p.Signed {
+		for _, key := range []string{"ik-s", "ik-t"} {
+			if _, ok := p.QueryParameters[key]; ok {
+				return fmt.Errorf("query parameter %s conflicts with the signature", key)
+			}
+		}
+	}
+
+	return nil
+}
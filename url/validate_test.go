@@ -0,0 +1,42 @@
+package url
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUrlParam_Validate(t *testing.T) {
+	var tr = []map[string]any{{"width": 400}}
+
+	var cases = map[string]struct {
+		params UrlParam
+		valid  bool
+	}{
+		"path":                 {UrlParam{Path: "/default-image.jpg", Transformations: tr}, true},
+		"src":                  {UrlParam{Src: "https://ik.imagekit.io/test/default-image.jpg", Transformations: tr}, true},
+		"path and src":         {UrlParam{Path: "/default-image.jpg", Src: "https://ik.imagekit.io/test/default-image.jpg"}, false},
+		"unknown position":     {UrlParam{Path: "/default-image.jpg", TransformationPosition: "header"}, false},
+		"query tr":             {UrlParam{Path: "/default-image.jpg", Transformations: tr, TransformationPosition: QUERY, QueryParameters: map[string]string{"tr": "h-100"}}, false},
+		"src tr":               {UrlParam{Src: "https://ik.imagekit.io/test/default-image.jpg", NamedTransformation: "thumb", QueryParameters: map[string]string{"tr": "h-100"}}, false},
+		"path tr":              {UrlParam{Path: "/default-image.jpg", Transformations: tr, QueryParameters: map[string]string{"tr": "h-100"}}, true},
+		"tr without transform": {UrlParam{Path: "/default-image.jpg", TransformationPosition: QUERY, QueryParameters: map[string]string{"tr": "h-100"}}, true},
+		"version":              {UrlParam{Path: "/default-image.jpg", Version: "v1", QueryParameters: map[string]string{"ik-obj-version": "v2"}}, false},
+		"signed ik-s":          {UrlParam{Path: "/default-image.jpg", Signed: true, QueryParameters: map[string]string{"ik-s": "x"}}, false},
+		"signed ik-t":          {UrlParam{Path: "/default-image.jpg", Signed: true, QueryParameters: map[string]string{"ik-t": "1"}}, false},
+		"attachment":           {UrlParam{Path: "/default-image.jpg", Attachment: true, QueryParameters: map[string]string{"ik-attachment": "false"}}, false},
+		"updated at":           {UrlParam{Path: "/default-image.jpg", UpdatedAt: time.UnixMilli(1), QueryParameters: map[string]string{"updatedAt": "2"}}, false},
+		"unsigned ik-s":        {UrlParam{Path: "/default-image.jpg", QueryParameters: map[string]string{"ik-s": "x"}}, true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.params.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package imagekit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidWebhookSignature is returned by VerifyWebhookEvent when the signature does not match
+// the body.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// WebhookEvent is an event parsed by ParseWebhookEvent. Use a type switch on the concrete types,
+// e.g. *VideoTransformationEvent, to handle it; events of types not modelled by the SDK are
+// returned as *UnknownWebhookEvent.
+type WebhookEvent interface {
+	EventType() string
+}
+
+// WebhookEventBase holds the fields common to all webhook events.
+type WebhookEventBase struct {
+	Type      string    `json:"type"`
+	Id        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EventType returns the type of the event, e.g. video.transformation.ready.
+func (e WebhookEventBase) EventType() string {
+	return e.Type
+}
+
+// VideoTransformationEvent is sent for the types video.transformation.accepted, .ready and
+// .error of a video transformation requested by url.
+type VideoTransformationEvent struct {
+	WebhookEventBase
+	Request struct {
+		Url        string `json:"url"`
+		XRequestId string `json:"x_request_id"`
+		UserAgent  string `json:"user_agent"`
+	} `json:"request"`
+	Data struct {
+		Asset struct {
+			Url string `json:"url"`
+		} `json:"asset"`
+		Transformation struct {
+			Type    string         `json:"type"`
+			Options map[string]any `json:"options"`
+			// Output is set by video.transformation.ready events.
+			Output *struct {
+				Url           string `json:"url"`
+				VideoMetadata struct {
+					Duration float64 `json:"duration"`
+					Width    int     `json:"width"`
+					Height   int     `json:"height"`
+					Bitrate  int     `json:"bitrate"`
+				} `json:"video_metadata"`
+			} `json:"output"`
+			// Error is set by video.transformation.error events.
+			Error *struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"transformation"`
+	} `json:"data"`
+}
+
+// UploadTransformEvent is sent for the types upload.pre-transform.success and .error, and
+// upload.post-transform.success and .error, of transformations requested with
+// uploader.UploadParam.Transformation.
+type UploadTransformEvent struct {
+	WebhookEventBase
+	Request struct {
+		XRequestId     string `json:"x_request_id"`
+		Transformation any    `json:"transformation"`
+	} `json:"request"`
+	Data struct {
+		FileId   string `json:"fileId"`
+		Name     string `json:"name"`
+		Url      string `json:"url"`
+		FilePath string `json:"filePath"`
+		// Transformation is set by error events.
+		Transformation *struct {
+			Error struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"transformation"`
+	} `json:"data"`
+}
+
+// Failed reports whether the event is of an error type.
+func (e UploadTransformEvent) Failed() bool {
+	return strings.HasSuffix(e.Type, ".error")
+}
+
+// UnknownWebhookEvent is an event of a type not modelled by the SDK, e.g. one recently added to
+// the api. Data holds the unparsed data of the event.
+type UnknownWebhookEvent struct {
+	WebhookEventBase
+	Data json.RawMessage `json:"data"`
+}
+
+// ParseWebhookEvent parses the body of a webhook request into the event struct of its type. The
+// signature is not checked, see VerifyWebhookEvent.
+func ParseWebhookEvent(body []byte) (WebhookEvent, error) {
+	var base WebhookEventBase
+	if err := json.Unmarshal(body, &base); err != nil {
+		return nil, err
+	}
+
+	if base.Type == "" {
+		return nil, errors.New("webhook event has no type")
+	}
+
+	var event WebhookEvent
+
+	switch {
+	case strings.HasPrefix(base.Type, "video.transformation."):
+		event = &VideoTransformationEvent{}
+	case strings.HasPrefix(base.Type, "upload.pre-transform."), strings.HasPrefix(base.Type, "upload.post-transform."):
+		event = &UploadTransformEvent{}
+	default:
+		event = &UnknownWebhookEvent{}
+	}
+
+	if err := json.Unmarshal(body, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// VerifyWebhookEvent checks signature, the value of the x-ik-signature header of a webhook
+// request, against body and the webhook secret of the account, and parses the event. Requests
+// signed more than tolerance ago are rejected, zero disables the check.
+func VerifyWebhookEvent(body []byte, signature string, secret string, tolerance time.Duration) (WebhookEvent, error) {
+	var timestamp, v1 string
+
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	if timestamp == "" || v1 == "" {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	expected, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	if tolerance > 0 {
+		ms, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidWebhookSignature
+		}
+
+		if age := time.Since(time.UnixMilli(ms)); age > tolerance || age < -tolerance {
+			return nil, errors.New("webhook signature timestamp is outside the tolerance")
+		}
+	}
+
+	return ParseWebhookEvent(body)
+}